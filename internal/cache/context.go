@@ -0,0 +1,57 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type contextKey struct{}
+
+type cacheability struct {
+	parent              *cacheability
+	assumeDeterministic bool
+	uncacheable         atomic.Bool
+}
+
+// Return a copy of ctx which allows to mark the computation using it as not cacheable (through MarkUncacheable() or MarkOpaque());
+// the returned function reports whether this happened. If ctx was itself derived from a context returned by WithCacheability()
+// (that is, if caches are nested), marks are propagated to the outer computations as well.
+// If assumeDeterministic is true, MarkOpaque() does not affect the returned context (nor outer ones).
+func WithCacheability(ctx context.Context, assumeDeterministic bool) (context.Context, func() bool) {
+	parent, _ := ctx.Value(contextKey{}).(*cacheability)
+	c := &cacheability{parent: parent, assumeDeterministic: assumeDeterministic}
+	return context.WithValue(ctx, contextKey{}, c), c.uncacheable.Load
+}
+
+// Mark the computation using ctx as not cacheable (for example because it depends on cluster state);
+// this is a no-op if ctx was not derived from a context returned by WithCacheability().
+func MarkUncacheable(ctx context.Context) {
+	for c, _ := ctx.Value(contextKey{}).(*cacheability); c != nil; c = c.parent {
+		c.uncacheable.Store(true)
+	}
+}
+
+// Mark the computation using ctx as not cacheable because it called code which cannot report uncacheable results itself
+// (such as generators or transformers which are not context-aware); computations declared deterministic (and outer ones) are not affected.
+// This is a no-op if ctx was not derived from a context returned by WithCacheability().
+func MarkOpaque(ctx context.Context) {
+	for c, _ := ctx.Value(contextKey{}).(*cacheability); c != nil && !c.assumeDeterministic; c = c.parent {
+		c.uncacheable.Store(true)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRU is a thread-safe cache holding at most a fixed number of entries; if full, the least recently used entry is evicted.
+type LRU[K comparable, V any] struct {
+	lock       sync.Mutex
+	maxEntries int
+	list       *list.List
+	entries    map[K]*list.Element
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// Create a new LRU cache holding at most maxEntries entries (must be positive).
+func NewLRU[K comparable, V any](maxEntries int) *LRU[K, V] {
+	if maxEntries <= 0 {
+		panic("maxEntries must be positive")
+	}
+	return &LRU[K, V]{
+		maxEntries: maxEntries,
+		list:       list.New(),
+		entries:    make(map[K]*list.Element),
+	}
+}
+
+// Get the value stored for the given key; the second return value indicates whether the key was found.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.list.MoveToFront(element)
+		return element.Value.(*lruEntry[K, V]).value, true
+	}
+	var value V
+	return value, false
+}
+
+// Store a value for the given key; if the cache is full, the least recently used entry is evicted.
+func (c *LRU[K, V]) Add(key K, value V) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.list.MoveToFront(element)
+		element.Value.(*lruEntry[K, V]).value = value
+		return
+	}
+	c.entries[key] = c.list.PushFront(&lruEntry[K, V]{key: key, value: value})
+	for c.list.Len() > c.maxEntries {
+		element := c.list.Back()
+		c.list.Remove(element)
+		delete(c.entries, element.Value.(*lruEntry[K, V]).key)
+	}
+}
+
+// Remove all entries.
+func (c *LRU[K, V]) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.list.Init()
+	c.entries = make(map[K]*list.Element)
+}
+
+// Return the number of entries.
+func (c *LRU[K, V]) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.list.Len()
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capabilities
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/sap/go-generics/slices"
+
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+)
+
+// Default time after which cached capabilities expire.
+const DefaultTTL = 5 * time.Minute
+
+// Capabilities of a cluster, as retrieved through discovery.
+type Capabilities struct {
+	// Server version.
+	KubeVersion *version.Info
+	// Supported api versions; contains the served group versions, and the served group versions followed by /<kind>.
+	APIVersions []string
+}
+
+// Provider retrieves cluster capabilities, and caches them per discovery client.
+// Returned capabilities are shared, and must not be modified by the caller.
+type Provider struct {
+	lock       sync.Mutex
+	ttl        time.Duration
+	entries    map[discovery.DiscoveryInterface]*entry
+	generation uint64
+}
+
+type entry struct {
+	capabilities *Capabilities
+	expiresAt    time.Time
+}
+
+var defaultProvider = NewProvider(DefaultTTL)
+
+// Create a new capabilities provider, caching capabilities for the given ttl.
+func NewProvider(ttl time.Duration) *Provider {
+	return &Provider{
+		ttl:     ttl,
+		entries: make(map[discovery.DiscoveryInterface]*entry),
+	}
+}
+
+// Get capabilities of the cluster targeted by the given discovery client; cached capabilities are returned if not yet expired.
+func (p *Provider) Get(client discovery.DiscoveryInterface) (*Capabilities, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := time.Now()
+	if e, ok := p.entries[client]; ok && now.Before(e.expiresAt) {
+		return e.capabilities, nil
+	}
+
+	// note: the lock is held while doing discovery, such that concurrent callers do not trigger multiple discovery sweeps
+	capabilities, err := discover(client)
+	if err != nil {
+		return nil, err
+	}
+	if e, ok := p.entries[client]; ok && !reflect.DeepEqual(e.capabilities, capabilities) {
+		p.generation++
+	}
+	p.entries[client] = &entry{capabilities: capabilities, expiresAt: now.Add(p.ttl)}
+	return capabilities, nil
+}
+
+// Invalidate all cached capabilities.
+func (p *Provider) Invalidate() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.entries = make(map[discovery.DiscoveryInterface]*entry)
+	p.generation++
+}
+
+// Return a counter which is increased whenever cached capabilities are invalidated, or were found to have changed upon expiry;
+// that is, results derived from capabilities returned by this provider are still valid as long as the generation is unchanged.
+func (p *Provider) Generation() uint64 {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.generation
+}
+
+// Get capabilities through the process-wide default provider.
+func Get(client discovery.DiscoveryInterface) (*Capabilities, error) {
+	return defaultProvider.Get(client)
+}
+
+// Invalidate capabilities cached by the process-wide default provider.
+func Invalidate() {
+	defaultProvider.Invalidate()
+}
+
+// Return the generation of the process-wide default provider.
+func Generation() uint64 {
+	return defaultProvider.Generation()
+}
+
+func discover(client discovery.DiscoveryInterface) (*Capabilities, error) {
+	kubeVersion, err := client.ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+	var apiVersions []string
+	_, apiResourceLists, err := client.ServerGroupsAndResources()
+	if err != nil {
+		return nil, err
+	}
+	for _, apiResourceList := range apiResourceLists {
+		apiVersions = append(apiVersions, apiResourceList.GroupVersion)
+		for _, apiResource := range apiResourceList.APIResources {
+			apiVersions = append(apiVersions, apiResourceList.GroupVersion+"/"+apiResource.Kind)
+		}
+	}
+	return &Capabilities{KubeVersion: kubeVersion, APIVersions: slices.Uniq(apiVersions)}, nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// read a gzipped tar archive, and return the contained regular files (keyed by their cleaned path)
+func readArchive(r io.Reader) (map[string][]byte, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+	tarReader := tar.NewReader(gzipReader)
+	files := make(map[string][]byte)
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.ReplaceAll(header.Name, "\\", "/"))
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("invalid file name in archive: %s", header.Name)
+		}
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = data
+	}
+	return files, nil
+}
+
+// split files of a chart archive into the name of the (single) top-level directory, and the files below that directory
+func splitArchive(files map[string][]byte) (map[string][]byte, string, error) {
+	dir := ""
+	for name := range files {
+		parts := strings.SplitN(name, "/", 2)
+		if len(parts) != 2 {
+			return nil, "", fmt.Errorf("archive contains file %s outside of chart directory", name)
+		}
+		if dir == "" {
+			dir = parts[0]
+		} else if parts[0] != dir {
+			return nil, "", fmt.Errorf("archive contains multiple top-level directories (%s, %s)", dir, parts[0])
+		}
+	}
+	if dir == "" {
+		return nil, "", fmt.Errorf("archive is empty")
+	}
+	return subfiles(files, dir), dir, nil
+}
@@ -17,34 +17,34 @@ limitations under the License.
 package helm
 
 import (
-	"github.com/sap/go-generics/slices"
+	"runtime"
 
 	"k8s.io/client-go/discovery"
+
+	"github.com/sap/component-operator-runtime/internal/capabilities"
 )
 
+// Helm version reported as .Capabilities.HelmVersion; this is the (fixed) helm version whose behavior is emulated,
+// and is not related to any helm library (the helm generator does not use the helm sdk).
+const HelmVersion = "v3.11.2"
+
+// Get capabilities of the cluster targeted by the given discovery client;
+// note: the returned data is built from the process-wide capabilities cache.
 func GetCapabilities(client discovery.DiscoveryInterface) (*CapabilitiesData, error) {
-	kubeVersion, err := client.ServerVersion()
-	if err != nil {
-		return nil, err
-	}
-	var apiVersions []string
-	_, apiResourceLists, err := client.ServerGroupsAndResources()
+	c, err := capabilities.Get(client)
 	if err != nil {
 		return nil, err
 	}
-	for _, apiResourceList := range apiResourceLists {
-		apiVersions = append(apiVersions, apiResourceList.GroupVersion)
-		for _, apiResource := range apiResourceList.APIResources {
-			apiVersions = append(apiVersions, apiResourceList.GroupVersion+"/"+apiResource.Kind)
-		}
-	}
-	capabilities := &CapabilitiesData{
+	return &CapabilitiesData{
 		KubeVersion: KubeVersionData{
-			Version: kubeVersion.GitVersion,
-			Major:   kubeVersion.Major,
-			Minor:   kubeVersion.Minor,
+			Version: c.KubeVersion.GitVersion,
+			Major:   c.KubeVersion.Major,
+			Minor:   c.KubeVersion.Minor,
 		},
-		APIVersions: slices.Uniq(apiVersions),
-	}
-	return capabilities, nil
+		APIVersions: c.APIVersions,
+		HelmVersion: VersionData{
+			Version:   HelmVersion,
+			GoVersion: runtime.Version(),
+		},
+	}, nil
 }
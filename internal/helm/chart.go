@@ -0,0 +1,300 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sap/go-generics/slices"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	kyaml "sigs.k8s.io/yaml"
+)
+
+// Chart represents a loaded Helm chart, including its subcharts.
+type Chart struct {
+	// Path of the chart (that is, the location it was loaded from); used in error messages.
+	Path string
+	// Full name of the chart; as with helm, this is the name of the top-level chart, followed by /charts/<name> for each level of subcharts;
+	// used to build template names.
+	FullName string
+	// Chart metadata (as read from Chart.yaml).
+	Metadata *ChartData
+	// Default values (as read from values.yaml).
+	Values map[string]any
+	// Values schema (as read from values.schema.json); nil if the chart has no schema.
+	Schema *spec.Schema
+	// Custom resource definitions (as read from crds/*.yaml).
+	Crds []*File
+	// Templates (as read from templates/[^_]*.yaml).
+	Templates []*File
+	// Template includes (as read from templates/_*).
+	Includes []*File
+	// Notes template (as read from templates/NOTES.txt); nil if the chart has no notes.
+	Notes *File
+	// Non-template files (all files except Chart.yaml, values.yaml, values.schema.json, and the contents of templates/ and charts/);
+	// exposed as .Files to templates.
+	Files []*File
+	// Subcharts (as read from charts/), resolved against the dependencies declared in Chart.yaml.
+	Dependencies []*Dependency
+}
+
+// File represents a file of a chart.
+type File struct {
+	// Path of the file; relative to the chart directory for chart files, prefixed with the full chart name for templates.
+	Name string
+	// Contents of the file.
+	Data []byte
+}
+
+// Dependency represents a subchart included by a chart.
+type Dependency struct {
+	// Name under which the subchart is included (that is, its alias, or the name of the subchart).
+	Name string
+	// Conditions (comma-separated value paths) controlling whether the subchart is enabled.
+	Condition string
+	// Tags controlling whether the subchart is enabled.
+	Tags []string
+	// Values to be imported from the subchart into the parent chart.
+	ImportValues []any
+	// The subchart itself.
+	Chart *Chart
+}
+
+// Load Helm chart from given directory of fsys.
+func LoadChart(fsys fs.FS, chartPath string) (*Chart, error) {
+	files := make(map[string][]byte)
+	if err := fs.WalkDir(fsys, chartPath, func(filePath string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !dirEntry.Type().IsRegular() {
+			return nil
+		}
+		raw, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return err
+		}
+		files[strings.TrimPrefix(filePath, chartPath+"/")] = raw
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return loadChart(files, chartPath, "", "")
+}
+
+// Load Helm chart from given gzipped tar archive (as created by 'helm package').
+func LoadChartFromArchive(r io.Reader) (*Chart, error) {
+	archiveFiles, err := readArchive(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading chart archive")
+	}
+	files, dir, err := splitArchive(archiveFiles)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading chart archive")
+	}
+	return loadChart(files, dir, "", "")
+}
+
+// load chart from given files (keyed by their path relative to the chart directory); the chart will be associated with the given path;
+// parentName is the full name of the parent chart (empty for top-level charts); if alias is not empty, it overrides the name of the chart
+// (as with helm, this affects the full name of the chart, and therefore its template names, as well as .Chart.Name)
+func loadChart(files map[string][]byte, chartPath string, parentName string, alias string) (*Chart, error) {
+	chart := &Chart{Path: chartPath}
+
+	chartRaw, ok := files["Chart.yaml"]
+	if !ok {
+		return nil, fmt.Errorf("chart %s: missing Chart.yaml", chartPath)
+	}
+	chart.Metadata = &ChartData{}
+	if err := kyaml.Unmarshal(chartRaw, chart.Metadata); err != nil {
+		return nil, errors.Wrapf(err, "chart %s: error parsing Chart.yaml", chartPath)
+	}
+	if chart.Metadata.Name == "" {
+		return nil, fmt.Errorf("chart %s: missing chart name in Chart.yaml", chartPath)
+	}
+	if alias != "" {
+		chart.Metadata.Name = alias
+	}
+	if parentName == "" {
+		chart.FullName = chart.Metadata.Name
+	} else {
+		chart.FullName = parentName + "/charts/" + chart.Metadata.Name
+	}
+
+	chart.Values = make(map[string]any)
+	if valuesRaw, ok := files["values.yaml"]; ok {
+		if err := kyaml.Unmarshal(valuesRaw, &chart.Values); err != nil {
+			return nil, errors.Wrapf(err, "chart %s: error parsing values.yaml", chartPath)
+		}
+		if chart.Values == nil {
+			chart.Values = make(map[string]any)
+		}
+	}
+
+	if schemaRaw, ok := files["values.schema.json"]; ok {
+		schema, err := parseSchema(schemaRaw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "chart %s: error parsing values.schema.json", chartPath)
+		}
+		chart.Schema = schema
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	subcharts := make(map[string]*subchart)
+	var subchartNames []string
+	for _, name := range names {
+		switch {
+		case matchPath("crds/*.yaml", name):
+			chart.Crds = append(chart.Crds, &File{Name: name, Data: files[name]})
+			// note: as with helm, custom resource definitions are accessible through .Files as well
+			chart.Files = append(chart.Files, &File{Name: name, Data: files[name]})
+		case matchPath("templates/[^_]*.yaml", name):
+			chart.Templates = append(chart.Templates, &File{Name: chart.FullName + "/" + name, Data: files[name]})
+		case matchPath("templates/_*", name):
+			chart.Includes = append(chart.Includes, &File{Name: chart.FullName + "/" + name, Data: files[name]})
+		case name == "Chart.yaml" || name == "Chart.lock" || name == "values.yaml" || name == "values.schema.json" || name == "requirements.yaml" || name == "requirements.lock":
+		case name == "templates/NOTES.txt":
+			chart.Notes = &File{Name: chart.FullName + "/" + name, Data: files[name]}
+		case strings.HasPrefix(name, "templates/"):
+		case matchPath("charts/*.tgz", name) || matchPath("charts/*.tar.gz", name):
+			archiveFiles, err := readArchive(bytes.NewReader(files[name]))
+			if err != nil {
+				return nil, errors.Wrapf(err, "chart %s: error reading subchart archive %s", chartPath, name)
+			}
+			subchartFiles, _, err := splitArchive(archiveFiles)
+			if err != nil {
+				return nil, errors.Wrapf(err, "chart %s: error reading subchart archive %s", chartPath, name)
+			}
+			if err := addSubchart(subcharts, &subchartNames, subchartFiles, chartPath+"/charts/"+name[len("charts/"):], chart.FullName); err != nil {
+				return nil, errors.Wrapf(err, "chart %s", chartPath)
+			}
+		case strings.HasPrefix(name, "charts/"):
+		default:
+			chart.Files = append(chart.Files, &File{Name: name, Data: files[name]})
+		}
+	}
+	for _, dir := range subdirs(files, "charts") {
+		if err := addSubchart(subcharts, &subchartNames, subfiles(files, "charts/"+dir), chartPath+"/charts/"+dir, chart.FullName); err != nil {
+			return nil, errors.Wrapf(err, "chart %s", chartPath)
+		}
+	}
+
+	// note: as with helm, subcharts which are not declared as dependency in Chart.yaml will be included as well;
+	// aliased subcharts are loaded once per alias, such that every alias gets its own copy of the subchart (named after the alias)
+	referenced := make(map[string]bool)
+	for _, dependencyData := range chart.Metadata.Dependencies {
+		subchart, ok := subcharts[dependencyData.Name]
+		if !ok {
+			return nil, fmt.Errorf("chart %s: dependency %s not found in charts directory", chartPath, dependencyData.Name)
+		}
+		referenced[dependencyData.Name] = true
+		dependency := &Dependency{
+			Name:         dependencyData.Name,
+			Condition:    dependencyData.Condition,
+			Tags:         dependencyData.Tags,
+			ImportValues: dependencyData.ImportValues,
+			Chart:        subchart.chart,
+		}
+		if dependencyData.Alias != "" {
+			aliasedChart, err := loadChart(subchart.files, subchart.path, chart.FullName, dependencyData.Alias)
+			if err != nil {
+				return nil, err
+			}
+			dependency.Name = dependencyData.Alias
+			dependency.Chart = aliasedChart
+		}
+		chart.Dependencies = append(chart.Dependencies, dependency)
+	}
+	for _, name := range subchartNames {
+		if !referenced[name] {
+			chart.Dependencies = append(chart.Dependencies, &Dependency{Name: name, Chart: subcharts[name].chart})
+		}
+	}
+
+	return chart, nil
+}
+
+// subchart found in the charts directory of a chart; files and path are retained, such that the subchart can be reloaded under an alias
+type subchart struct {
+	chart *Chart
+	files map[string][]byte
+	path  string
+}
+
+// load subchart from given files, and add it to subcharts (keyed by its name)
+func addSubchart(subcharts map[string]*subchart, names *[]string, files map[string][]byte, chartPath string, parentName string) error {
+	chart, err := loadChart(files, chartPath, parentName, "")
+	if err != nil {
+		return err
+	}
+	name := chart.Metadata.Name
+	if _, ok := subcharts[name]; ok {
+		return fmt.Errorf("duplicate subchart %s", name)
+	}
+	subcharts[name] = &subchart{chart: chart, files: files, path: chartPath}
+	*names = append(*names, name)
+	return nil
+}
+
+// return the (sorted) names of the immediate subdirectories of dir
+func subdirs(files map[string][]byte, dir string) []string {
+	var dirs []string
+	for name := range files {
+		if !strings.HasPrefix(name, dir+"/") {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(name, dir+"/"), "/", 2)
+		if len(parts) == 2 && !slices.Contains(dirs, parts[0]) {
+			dirs = append(dirs, parts[0])
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// return the files below dir (keyed by their path relative to dir)
+func subfiles(files map[string][]byte, dir string) map[string][]byte {
+	result := make(map[string][]byte)
+	for name, data := range files {
+		if strings.HasPrefix(name, dir+"/") {
+			result[strings.TrimPrefix(name, dir+"/")] = data
+		}
+	}
+	return result
+}
+
+func matchPath(pattern string, name string) bool {
+	match, err := path.Match(pattern, name)
+	if err != nil {
+		panic("this cannot happen")
+		// because an error would occur only if the pattern is malformed, which is not the case
+	}
+	return match
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"encoding/base64"
+	"path"
+	"strings"
+
+	"github.com/gobwas/glob"
+
+	kyaml "sigs.k8s.io/yaml"
+)
+
+// Files provides access to the non-template files of a chart (exposed as .Files to templates);
+// the methods are compatible with the according Helm API.
+type Files map[string][]byte
+
+// Create Files object from given chart files.
+func NewFiles(files []*File) Files {
+	f := make(Files)
+	for _, file := range files {
+		f[file.Name] = file.Data
+	}
+	return f
+}
+
+// Get the contents of the given file as byte slice; returns nil if the file does not exist.
+func (f Files) GetBytes(name string) []byte {
+	if data, ok := f[name]; ok {
+		return data
+	}
+	return nil
+}
+
+// Get the contents of the given file as string; returns an empty string if the file does not exist.
+func (f Files) Get(name string) string {
+	return string(f.GetBytes(name))
+}
+
+// Return all files whose name matches the given glob pattern (where '**' matches across path separators).
+func (f Files) Glob(pattern string) Files {
+	g, err := glob.Compile(pattern, '/')
+	if err != nil {
+		g, _ = glob.Compile("**")
+	}
+	result := make(Files)
+	for name, data := range f {
+		if g.Match(name) {
+			result[name] = data
+		}
+	}
+	return result
+}
+
+// Return the files as YAML map (suitable as data of a config map), keyed by the base names of the files.
+func (f Files) AsConfig() string {
+	if f == nil {
+		return ""
+	}
+	m := make(map[string]string)
+	for name, data := range f {
+		m[path.Base(name)] = string(data)
+	}
+	return toYaml(m)
+}
+
+// Return the files as YAML map (suitable as data of a secret), keyed by the base names of the files, with base64 encoded contents.
+func (f Files) AsSecrets() string {
+	if f == nil {
+		return ""
+	}
+	m := make(map[string]string)
+	for name, data := range f {
+		m[path.Base(name)] = base64.StdEncoding.EncodeToString(data)
+	}
+	return toYaml(m)
+}
+
+// Return the lines of the given file; returns an empty slice if the file does not exist.
+func (f Files) Lines(name string) []string {
+	if f == nil || f[name] == nil {
+		return []string{}
+	}
+	s := string(f[name])
+	if s[len(s)-1] == '\n' {
+		s = s[:len(s)-1]
+	}
+	return strings.Split(s, "\n")
+}
+
+func toYaml(v any) string {
+	data, err := kyaml.Marshal(v)
+	if err != nil {
+		// note: this is how helm behaves
+		return ""
+	}
+	return strings.TrimSuffix(string(data), "\n")
+}
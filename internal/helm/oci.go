@@ -0,0 +1,183 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ociImageLayoutVersion           = "1.0.0"
+	ociMediaTypeImageIndex          = "application/vnd.oci.image.index.v1+json"
+	ociMediaTypeImageManifest       = "application/vnd.oci.image.manifest.v1+json"
+	ociAnnotationKeyRefName         = "org.opencontainers.image.ref.name"
+	helmMediaTypeChartContent       = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	helmMediaTypeChartContentLegacy = "application/tar+gzip"
+)
+
+type ociImageLayout struct {
+	ImageLayoutVersion string `json:"imageLayoutVersion"`
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociIndex struct {
+	MediaType string           `json:"mediaType,omitempty"`
+	Manifests []*ociDescriptor `json:"manifests"`
+}
+
+type ociManifest struct {
+	MediaType string           `json:"mediaType,omitempty"`
+	Config    *ociDescriptor   `json:"config"`
+	Layers    []*ociDescriptor `json:"layers"`
+}
+
+// Load Helm chart from given OCI image layout directory of fsys (as specified by https://github.com/opencontainers/image-spec/blob/main/image-layout.md).
+// The chart manifest is selected by reference, which may be a tag (matched against the org.opencontainers.image.ref.name annotation) or a digest;
+// if reference is empty, the layout must contain exactly one manifest. The digests (and sizes) of all read blobs are verified.
+func LoadChartFromOciLayout(fsys fs.FS, layoutPath string, reference string) (*Chart, error) {
+	layoutRaw, err := fs.ReadFile(fsys, path.Join(layoutPath, "oci-layout"))
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading oci layout")
+	}
+	layout := &ociImageLayout{}
+	if err := json.Unmarshal(layoutRaw, layout); err != nil {
+		return nil, errors.Wrap(err, "error parsing oci layout")
+	}
+	if layout.ImageLayoutVersion != ociImageLayoutVersion {
+		return nil, fmt.Errorf("unsupported oci layout version: %s", layout.ImageLayoutVersion)
+	}
+
+	indexRaw, err := fs.ReadFile(fsys, path.Join(layoutPath, "index.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading oci index")
+	}
+	descriptor, err := selectOciManifest(fsys, layoutPath, indexRaw, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestRaw, err := readOciBlob(fsys, layoutPath, descriptor)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &ociManifest{}
+	if err := json.Unmarshal(manifestRaw, manifest); err != nil {
+		return nil, errors.Wrapf(err, "error parsing oci manifest %s", descriptor.Digest)
+	}
+	var layer *ociDescriptor
+	for _, l := range manifest.Layers {
+		if l.MediaType == helmMediaTypeChartContent || l.MediaType == helmMediaTypeChartContentLegacy {
+			if layer != nil {
+				return nil, fmt.Errorf("oci manifest %s contains more than one chart layer", descriptor.Digest)
+			}
+			layer = l
+		}
+	}
+	if layer == nil {
+		return nil, fmt.Errorf("oci manifest %s contains no chart layer (media type %s)", descriptor.Digest, helmMediaTypeChartContent)
+	}
+	layerRaw, err := readOciBlob(fsys, layoutPath, layer)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadChartFromArchive(bytes.NewReader(layerRaw))
+}
+
+// select manifest from given index; nested indexes are resolved
+func selectOciManifest(fsys fs.FS, layoutPath string, indexRaw []byte, reference string) (*ociDescriptor, error) {
+	index := &ociIndex{}
+	if err := json.Unmarshal(indexRaw, index); err != nil {
+		return nil, errors.Wrap(err, "error parsing oci index")
+	}
+	var candidates []*ociDescriptor
+	for _, descriptor := range index.Manifests {
+		if reference == "" || descriptor.Digest == reference || descriptor.Annotations[ociAnnotationKeyRefName] == reference {
+			candidates = append(candidates, descriptor)
+		}
+	}
+	switch {
+	case len(candidates) == 0 && reference == "":
+		return nil, fmt.Errorf("oci index contains no manifests")
+	case len(candidates) == 0:
+		return nil, fmt.Errorf("oci index contains no manifest matching reference %s", reference)
+	case len(candidates) > 1 && reference == "":
+		return nil, fmt.Errorf("oci index contains multiple manifests; a reference must be specified")
+	case len(candidates) > 1:
+		return nil, fmt.Errorf("oci index contains multiple manifests matching reference %s", reference)
+	}
+	descriptor := candidates[0]
+	switch descriptor.MediaType {
+	case ociMediaTypeImageManifest:
+		return descriptor, nil
+	case ociMediaTypeImageIndex:
+		nestedIndexRaw, err := readOciBlob(fsys, layoutPath, descriptor)
+		if err != nil {
+			return nil, err
+		}
+		// note: the reference applies to the top-level index only
+		return selectOciManifest(fsys, layoutPath, nestedIndexRaw, "")
+	default:
+		return nil, fmt.Errorf("unsupported media type %s of oci descriptor %s", descriptor.MediaType, descriptor.Digest)
+	}
+}
+
+// read blob referenced by given descriptor, and verify its digest and size
+func readOciBlob(fsys fs.FS, layoutPath string, descriptor *ociDescriptor) ([]byte, error) {
+	algorithm, encoded, ok := strings.Cut(descriptor.Digest, ":")
+	if !ok || encoded == "" || strings.ContainsAny(encoded, "/\\.") {
+		return nil, fmt.Errorf("invalid digest: %s", descriptor.Digest)
+	}
+	var h hash.Hash
+	switch algorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return nil, fmt.Errorf("unsupported digest algorithm: %s", algorithm)
+	}
+	data, err := fs.ReadFile(fsys, path.Join(layoutPath, "blobs", algorithm, encoded))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading oci blob %s", descriptor.Digest)
+	}
+	if int64(len(data)) != descriptor.Size {
+		return nil, fmt.Errorf("size mismatch for oci blob %s (expected: %d, actual: %d)", descriptor.Digest, descriptor.Size, len(data))
+	}
+	h.Write(data)
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != encoded {
+		return nil, fmt.Errorf("digest mismatch for oci blob %s (actual: %s:%s)", descriptor.Digest, algorithm, actual)
+	}
+	return data, nil
+}
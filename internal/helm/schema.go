@@ -0,0 +1,187 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	openapierrors "k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+// Validate the values of the given scoped charts against the schemas of the according charts (if any);
+// the returned error lists all violations, qualified by their path within the values of the top-level chart.
+func ValidateValues(scopedCharts []*ScopedChart) error {
+	var messages []string
+	for _, scopedChart := range scopedCharts {
+		if scopedChart.Chart.Schema == nil {
+			continue
+		}
+		var values any = scopedChart.Values
+		if scopedChart.Values == nil {
+			values = map[string]any{}
+		}
+		result := validate.NewSchemaValidator(scopedChart.Chart.Schema, nil, scopedChart.Path, strfmt.Default).Validate(values)
+		for _, err := range result.Errors {
+			messages = append(messages, formatSchemaError(scopedChart.Path, err))
+		}
+	}
+	if len(messages) > 0 {
+		sort.Strings(messages)
+		return fmt.Errorf("values do not match chart schema: %s", strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+var exclusiveBoundKeys = map[string]string{
+	"exclusiveMinimum": "minimum",
+	"exclusiveMaximum": "maximum",
+}
+
+func formatSchemaError(path string, err error) string {
+	message := err.Error()
+	if validationErr, ok := err.(*openapierrors.Validation); ok {
+		// messages of validation errors are of the form "<path> in <location> <text>"
+		separator := " in " + validationErr.In + " "
+		if i := strings.Index(message, separator); i >= 0 {
+			path = strings.TrimPrefix(message[:i], ".")
+			message = message[i+len(separator):]
+		}
+	}
+	if path == "" {
+		path = "(root)"
+	}
+	return path + ": " + message
+}
+
+// parse a values schema (JSON schema); local references are resolved, and a few newer keywords are translated
+// into their draft-04 equivalents (such that the schema can be handled by the openapi validator)
+func parseSchema(raw []byte) (*spec.Schema, error) {
+	var document any
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, err
+	}
+	document, err := normalizeSchema(document, document, nil)
+	if err != nil {
+		return nil, err
+	}
+	raw, err = json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	schema := &spec.Schema{}
+	if err := json.Unmarshal(raw, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func normalizeSchema(node any, document any, refs []string) (any, error) {
+	switch node := node.(type) {
+	case map[string]any:
+		if ref, ok := node["$ref"].(string); ok {
+			if !strings.HasPrefix(ref, "#") {
+				return nil, fmt.Errorf("unsupported schema reference %s (only local references are supported)", ref)
+			}
+			for _, r := range refs {
+				if r == ref {
+					return nil, fmt.Errorf("unsupported schema reference %s (recursive references are not supported)", ref)
+				}
+			}
+			target, err := resolveSchemaReference(document, ref)
+			if err != nil {
+				return nil, err
+			}
+			return normalizeSchema(target, document, append(refs, ref))
+		}
+		result := make(map[string]any)
+		for key, value := range node {
+			switch key {
+			case "$schema", "$id", "$comment", "definitions", "$defs":
+				// not needed (references are resolved inline)
+			case "enum", "default", "example", "examples", "required", "type", "format", "pattern", "title", "description":
+				result[key] = value
+			case "const":
+				result["enum"] = []any{value}
+			case "exclusiveMinimum", "exclusiveMaximum":
+				// newer drafts specify the exclusive bound as number, draft-04 uses a boolean flag
+				if number, ok := value.(float64); ok {
+					result[exclusiveBoundKeys[key]] = number
+					result[key] = true
+				} else {
+					result[key] = value
+				}
+			case "properties", "patternProperties":
+				properties, ok := value.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("invalid value for schema keyword %s", key)
+				}
+				normalizedProperties := make(map[string]any)
+				for name, property := range properties {
+					property, err := normalizeSchema(property, document, refs)
+					if err != nil {
+						return nil, err
+					}
+					normalizedProperties[name] = property
+				}
+				result[key] = normalizedProperties
+			default:
+				value, err := normalizeSchema(value, document, refs)
+				if err != nil {
+					return nil, err
+				}
+				result[key] = value
+			}
+		}
+		return result, nil
+	case []any:
+		result := make([]any, len(node))
+		for i, value := range node {
+			value, err := normalizeSchema(value, document, refs)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = value
+		}
+		return result, nil
+	default:
+		return node, nil
+	}
+}
+
+func resolveSchemaReference(document any, ref string) (any, error) {
+	node := document
+	for _, token := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(ref, "#"), "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid schema reference %s", ref)
+		}
+		if node, ok = m[token]; !ok {
+			return nil, fmt.Errorf("invalid schema reference %s", ref)
+		}
+	}
+	return node, nil
+}
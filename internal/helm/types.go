@@ -17,9 +17,40 @@ limitations under the License.
 package helm
 
 type ChartData struct {
-	Name       string `json:"name,omitempty"`
-	Version    string `json:"version,omitempty"`
-	AppVersion string `json:"appVersion,omitempty"`
+	APIVersion   string                 `json:"apiVersion,omitempty"`
+	Name         string                 `json:"name,omitempty"`
+	Home         string                 `json:"home,omitempty"`
+	Sources      []string               `json:"sources,omitempty"`
+	Version      string                 `json:"version,omitempty"`
+	Description  string                 `json:"description,omitempty"`
+	Keywords     []string               `json:"keywords,omitempty"`
+	Maintainers  []*MaintainerData      `json:"maintainers,omitempty"`
+	Icon         string                 `json:"icon,omitempty"`
+	Condition    string                 `json:"condition,omitempty"`
+	Tags         string                 `json:"tags,omitempty"`
+	AppVersion   string                 `json:"appVersion,omitempty"`
+	Deprecated   bool                   `json:"deprecated,omitempty"`
+	Annotations  map[string]string      `json:"annotations,omitempty"`
+	KubeVersion  string                 `json:"kubeVersion,omitempty"`
+	Dependencies []*ChartDependencyData `json:"dependencies,omitempty"`
+	Type         string                 `json:"type,omitempty"`
+}
+
+type MaintainerData struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+type ChartDependencyData struct {
+	Name         string   `json:"name"`
+	Version      string   `json:"version,omitempty"`
+	Repository   string   `json:"repository,omitempty"`
+	Condition    string   `json:"condition,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Enabled      bool     `json:"enabled,omitempty"`
+	ImportValues []any    `json:"import-values,omitempty"`
+	Alias        string   `json:"alias,omitempty"`
 }
 
 type TemplateData struct {
@@ -31,11 +62,15 @@ type ReleaseData struct {
 	Namespace string `json:"namespace,omitempty"`
 	Name      string `json:"name,omitempty"`
 	Service   string `json:"service,omitempty"`
+	IsInstall bool   `json:"isInstall,omitempty"`
+	IsUpgrade bool   `json:"isUpgrade,omitempty"`
+	Revision  int    `json:"revision,omitempty"`
 }
 
 type CapabilitiesData struct {
 	KubeVersion KubeVersionData `json:"kubeVersion,omitempty"`
 	APIVersions ApiVersionsData `json:"apiVersions,omitempty"`
+	HelmVersion VersionData     `json:"helmVersion,omitempty"`
 }
 
 type ApiVersionsData []string
@@ -59,6 +94,18 @@ func (kubeVersion *KubeVersionData) String() string {
 	return kubeVersion.Version
 }
 
+// Deprecated: use Version instead (provided for compatibility with helm).
+func (kubeVersion *KubeVersionData) GitVersion() string {
+	return kubeVersion.Version
+}
+
+type VersionData struct {
+	Version      string `json:"version,omitempty"`
+	GitCommit    string `json:"gitCommit,omitempty"`
+	GitTreeState string `json:"gitTreeState,omitempty"`
+	GoVersion    string `json:"goVersion,omitempty"`
+}
+
 type HookMetadata struct {
 	Types          []string
 	Weight         int
@@ -0,0 +1,257 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"fmt"
+	"strings"
+)
+
+const globalValuesKey = "global"
+
+// ScopedChart represents an enabled chart (or subchart) of a release, together with the values to be used when rendering it.
+type ScopedChart struct {
+	// The chart.
+	Chart *Chart
+	// Chart metadata; as with helm, the name is replaced by the alias of the subchart (if an alias is defined).
+	Metadata *ChartData
+	// Values (scoped to the chart).
+	Values map[string]any
+	// Path of the scoped values within the values of the top-level chart (empty for the top-level chart).
+	Path string
+}
+
+// Compute values for the given chart and its subcharts, in a helm compatible way. That means:
+//   - the given values are merged with the default values of the chart and its subcharts (the given values take precedence)
+//   - global values are propagated from parent charts to subcharts
+//   - subcharts are enabled or disabled according to the conditions and tags declared in the dependencies
+//   - values are imported from subcharts into parent charts, according to the import-values declared in the dependencies.
+//
+// Returns the enabled charts (starting with the given chart), together with their scoped values.
+// The given values will not be changed.
+func ProcessValues(chart *Chart, values map[string]any) ([]*ScopedChart, error) {
+	disabled := make(map[string]bool)
+
+	topValues := coalesceValues(chart, values, "", disabled)
+	evaluateDependencies(chart, topValues, "", disabled)
+	topValues = coalesceValues(chart, values, "", disabled)
+	if err := importValues(chart, topValues, "", disabled); err != nil {
+		return nil, err
+	}
+
+	var scopedCharts []*ScopedChart
+	collectScopedCharts(chart, chart.Metadata, topValues, "", disabled, &scopedCharts)
+	return scopedCharts, nil
+}
+
+func coalesceValues(chart *Chart, values map[string]any, path string, disabled map[string]bool) map[string]any {
+	result := mergeValues(chart.Values, values)
+	globals, _ := result[globalValuesKey].(map[string]any)
+	for _, dependency := range chart.Dependencies {
+		dependencyPath := joinPath(path, dependency.Name)
+		if disabled[dependencyPath] {
+			continue
+		}
+		dependencyValues, _ := result[dependency.Name].(map[string]any)
+		if globals != nil {
+			// note: globals of parent charts take precedence over globals of subcharts
+			dependencyValues = mergeValues(dependencyValues, map[string]any{globalValuesKey: globals})
+		}
+		result[dependency.Name] = coalesceValues(dependency.Chart, dependencyValues, dependencyPath, disabled)
+	}
+	return result
+}
+
+func evaluateDependencies(chart *Chart, topValues map[string]any, path string, disabled map[string]bool) {
+	tags, _ := topValues["tags"].(map[string]any)
+	for _, dependency := range chart.Dependencies {
+		dependencyPath := joinPath(path, dependency.Name)
+		enabled := true
+		// as with helm, a dependency is disabled by tags if none of its tags is true, and at least one of its tags is false
+		hasTrue := false
+		hasFalse := false
+		for _, tag := range dependency.Tags {
+			if value, ok := tags[tag].(bool); ok {
+				if value {
+					hasTrue = true
+				} else {
+					hasFalse = true
+				}
+			}
+		}
+		if !hasTrue && hasFalse {
+			enabled = false
+		}
+		// as with helm, the first condition resolving to a boolean value takes precedence over tags
+		for _, condition := range strings.Split(dependency.Condition, ",") {
+			condition = strings.TrimSpace(condition)
+			if condition == "" {
+				continue
+			}
+			if value, ok := lookupValue(topValues, joinPath(path, condition)).(bool); ok {
+				enabled = value
+				break
+			}
+		}
+		if !enabled {
+			disabled[dependencyPath] = true
+			continue
+		}
+		evaluateDependencies(dependency.Chart, topValues, dependencyPath, disabled)
+	}
+}
+
+// import values from (enabled) subcharts into values (in place); values of the parent chart take precedence over imported values
+func importValues(chart *Chart, values map[string]any, path string, disabled map[string]bool) error {
+	imported := make(map[string]any)
+	for _, dependency := range chart.Dependencies {
+		dependencyPath := joinPath(path, dependency.Name)
+		if disabled[dependencyPath] {
+			continue
+		}
+		dependencyValues, _ := values[dependency.Name].(map[string]any)
+		if err := importValues(dependency.Chart, dependencyValues, dependencyPath, disabled); err != nil {
+			return err
+		}
+		for _, importValue := range dependency.ImportValues {
+			childPath := ""
+			parentPath := ""
+			switch importValue := importValue.(type) {
+			case string:
+				childPath = "exports." + importValue
+			case map[string]any:
+				childPath, _ = importValue["child"].(string)
+				parentPath, _ = importValue["parent"].(string)
+				if childPath == "" || parentPath == "" {
+					return fmt.Errorf("invalid import-values for dependency %s (child and parent must be specified)", dependencyPath)
+				}
+			default:
+				return fmt.Errorf("invalid import-values for dependency %s (must be a string or a map)", dependencyPath)
+			}
+			// note: as with helm, missing child values are silently ignored
+			if table, ok := lookupValue(dependencyValues, childPath).(map[string]any); ok {
+				// note: values imported earlier take precedence
+				imported = mergeValues(buildValue(parentPath, table), imported)
+			}
+		}
+	}
+	if len(imported) > 0 {
+		for key, value := range mergeValues(imported, values) {
+			values[key] = value
+		}
+	}
+	return nil
+}
+
+func collectScopedCharts(chart *Chart, metadata *ChartData, values map[string]any, path string, disabled map[string]bool, scopedCharts *[]*ScopedChart) {
+	// note: as with helm, the enabled flag of the declared dependencies reflects the evaluation of conditions and tags
+	scopedMetadata := *metadata
+	scopedMetadata.Dependencies = nil
+	for _, dependencyData := range metadata.Dependencies {
+		scopedDependencyData := *dependencyData
+		name := dependencyData.Name
+		if dependencyData.Alias != "" {
+			name = dependencyData.Alias
+		}
+		scopedDependencyData.Enabled = !disabled[joinPath(path, name)]
+		scopedMetadata.Dependencies = append(scopedMetadata.Dependencies, &scopedDependencyData)
+	}
+	*scopedCharts = append(*scopedCharts, &ScopedChart{Chart: chart, Metadata: &scopedMetadata, Values: values, Path: path})
+	for _, dependency := range chart.Dependencies {
+		dependencyPath := joinPath(path, dependency.Name)
+		if disabled[dependencyPath] {
+			continue
+		}
+		dependencyMetadata := *dependency.Chart.Metadata
+		dependencyMetadata.Name = dependency.Name
+		dependencyValues, _ := values[dependency.Name].(map[string]any)
+		collectScopedCharts(dependency.Chart, &dependencyMetadata, dependencyValues, dependencyPath, disabled, scopedCharts)
+	}
+}
+
+// deep-merge y into x, and return the result; as with helm, null values in y remove the according keys;
+// the maps given as input will not be changed
+func mergeValues(x map[string]any, y map[string]any) map[string]any {
+	result := make(map[string]any)
+	for k, v := range x {
+		result[k] = copyValue(v)
+	}
+	for k, w := range y {
+		if w == nil {
+			delete(result, k)
+			continue
+		}
+		if v, ok := result[k].(map[string]any); ok {
+			if w, ok := w.(map[string]any); ok {
+				result[k] = mergeValues(v, w)
+				continue
+			}
+		}
+		result[k] = copyValue(w)
+	}
+	return result
+}
+
+func copyValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		result := make(map[string]any)
+		for k, w := range v {
+			result[k] = copyValue(w)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, w := range v {
+			result[i] = copyValue(w)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+func lookupValue(values map[string]any, path string) any {
+	var value any = values
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+func buildValue(path string, value map[string]any) map[string]any {
+	path = strings.Trim(path, ".")
+	if path == "" {
+		return value
+	}
+	keys := strings.Split(path, ".")
+	for i := len(keys) - 1; i >= 0; i-- {
+		value = map[string]any{keys[i]: value}
+	}
+	return value
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
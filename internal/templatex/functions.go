@@ -17,12 +17,14 @@ limitations under the License.
 package templatex
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
 	"text/template"
 
+	"github.com/BurntSushi/toml"
 	"github.com/pkg/errors"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -30,16 +32,23 @@ import (
 	apitypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	kyaml "sigs.k8s.io/yaml"
+
+	"github.com/sap/component-operator-runtime/internal/cache"
 )
 
 // template FuncMap generator
 func FuncMap() template.FuncMap {
 	return template.FuncMap{
-		"toYaml":   toYaml,
-		"fromYaml": fromYaml,
-		"toJson":   toJson,
-		"fromJson": fromJson,
-		"required": required,
+		"toYaml":        toYaml,
+		"mustToYaml":    mustToYaml,
+		"fromYaml":      fromYaml,
+		"fromYamlArray": fromYamlArray,
+		"toJson":        toJson,
+		"mustToJson":    toJson,
+		"fromJson":      fromJson,
+		"fromJsonArray": fromJsonArray,
+		"toToml":        toToml,
+		"required":      required,
 	}
 }
 
@@ -53,9 +62,26 @@ func FuncMapForTemplate(t *template.Template) template.FuncMap {
 
 // template FuncMap generator for functions called in a Kubernetes context
 func FuncMapForClient(c client.Client) template.FuncMap {
+	return FuncMapForClientWithContext(context.Background(), c)
+}
+
+// template FuncMap generator for functions called in a Kubernetes context; API calls use the given context
+func FuncMapForClientWithContext(ctx context.Context, c client.Client) template.FuncMap {
 	return template.FuncMap{
-		"lookup": makeFuncLookup(c),
+		"lookup": makeFuncLookup(ctx, c),
+	}
+}
+
+// Clone the given template (together with all associated templates), and rebind the template and Kubernetes specific functions
+// to the clone; API calls made by the clone use the given context and client.
+func CloneWithContext(ctx context.Context, t *template.Template, c client.Client) (*template.Template, error) {
+	clone, err := t.Clone()
+	if err != nil {
+		return nil, err
 	}
+	// note: the function map is shared by all associated templates, so it is sufficient to update it on one of them
+	clone.Funcs(FuncMapForTemplate(clone)).Funcs(FuncMapForClientWithContext(ctx, c))
+	return clone, nil
 }
 
 func toYaml(data any) (string, error) {
@@ -66,6 +92,16 @@ func toYaml(data any) (string, error) {
 	return strings.TrimSuffix(string(raw), "\n"), nil
 }
 
+// as with helm, mustToYaml fails the template if the given value cannot be marshalled
+// (note that, other than with helm, toYaml fails as well in that case)
+func mustToYaml(data any) (string, error) {
+	raw, err := kyaml.Marshal(data)
+	if err != nil {
+		return "", errors.Wrap(err, "error marshalling value to yaml")
+	}
+	return strings.TrimSuffix(string(raw), "\n"), nil
+}
+
 func fromYaml(data string) (map[string]any, error) {
 	var res map[string]any
 	if err := kyaml.Unmarshal([]byte(data), &res); err != nil {
@@ -74,6 +110,14 @@ func fromYaml(data string) (map[string]any, error) {
 	return res, nil
 }
 
+func fromYamlArray(data string) ([]any, error) {
+	var res []any
+	if err := kyaml.Unmarshal([]byte(data), &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 func toJson(data any) (string, error) {
 	raw, err := json.Marshal(data)
 	if err != nil {
@@ -90,6 +134,22 @@ func fromJson(data string) (map[string]any, error) {
 	return res, nil
 }
 
+func fromJsonArray(data string) ([]any, error) {
+	var res []any
+	if err := json.Unmarshal([]byte(data), &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func toToml(data any) (string, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 func required(warn string, data any) (any, error) {
 	if data == nil {
 		return data, errors.New(warn)
@@ -138,12 +198,14 @@ func makeFuncTpl(t *template.Template) func(string, any) (string, error) {
 	}
 }
 
-func makeFuncLookup(c client.Client) func(string, string, string, string) (map[string]any, error) {
+func makeFuncLookup(ctx context.Context, c client.Client) func(string, string, string, string) (map[string]any, error) {
 	return func(apiVersion string, kind string, namespace string, name string) (map[string]any, error) {
+		// note: the result depends on cluster state, so it must not be cached
+		cache.MarkUncacheable(ctx)
 		object := &unstructured.Unstructured{}
 		object.SetAPIVersion(apiVersion)
 		object.SetKind(kind)
-		if err := c.Get(context.Background(), apitypes.NamespacedName{Namespace: namespace, Name: name}, object); err != nil {
+		if err := c.Get(ctx, apitypes.NamespacedName{Namespace: namespace, Name: name}, object); err != nil {
 			if apierrors.IsNotFound(err) {
 				err = nil
 			}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package component
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/sap/go-generics/slices"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// check whether updating existingCrd to crd would make existing custom resources unreadable (or invalid);
+// return a list of human-readable descriptions of the found incompatibilities
+func checkCrdUpgrade(existingCrd *apiextensionsv1.CustomResourceDefinition, crd *apiextensionsv1.CustomResourceDefinition) []string {
+	var violations []string
+
+	if existingCrd.Spec.Scope != crd.Spec.Scope {
+		violations = append(violations, fmt.Sprintf("scope changed from %s to %s", existingCrd.Spec.Scope, crd.Spec.Scope))
+	}
+
+	versions := make(map[string]*apiextensionsv1.CustomResourceDefinitionVersion)
+	for i := range crd.Spec.Versions {
+		versions[crd.Spec.Versions[i].Name] = &crd.Spec.Versions[i]
+	}
+
+	for _, existingVersion := range existingCrd.Spec.Versions {
+		if !existingVersion.Served {
+			continue
+		}
+		version, ok := versions[existingVersion.Name]
+		if !ok || !version.Served {
+			violations = append(violations, fmt.Sprintf("served version %s removed", existingVersion.Name))
+			continue
+		}
+		if existingVersion.Schema != nil && existingVersion.Schema.OpenAPIV3Schema != nil && version.Schema != nil && version.Schema.OpenAPIV3Schema != nil {
+			for _, violation := range checkSchemaUpgrade("", existingVersion.Schema.OpenAPIV3Schema, version.Schema.OpenAPIV3Schema) {
+				violations = append(violations, fmt.Sprintf("version %s: %s", existingVersion.Name, violation))
+			}
+		}
+	}
+
+	for _, storedVersion := range existingCrd.Status.StoredVersions {
+		if _, ok := versions[storedVersion]; !ok {
+			violations = append(violations, fmt.Sprintf("stored version %s removed (still listed in status.storedVersions)", storedVersion))
+		}
+	}
+
+	return violations
+}
+
+// check whether the schema change from existingSchema to schema is compatible, with respect to types and required fields
+func checkSchemaUpgrade(path string, existingSchema *apiextensionsv1.JSONSchemaProps, schema *apiextensionsv1.JSONSchemaProps) []string {
+	var violations []string
+
+	if existingSchema.Type != "" && schema.Type != "" && existingSchema.Type != schema.Type {
+		violations = append(violations, fmt.Sprintf("type of field %s changed from %s to %s", formatSchemaPath(path), existingSchema.Type, schema.Type))
+		return violations
+	}
+
+	for _, name := range schema.Required {
+		if !slices.Contains(existingSchema.Required, name) {
+			violations = append(violations, fmt.Sprintf("field %s became required", formatSchemaPath(path+"."+name)))
+		}
+	}
+	for _, name := range existingSchema.Required {
+		if _, ok := existingSchema.Properties[name]; !ok {
+			continue
+		}
+		if _, ok := schema.Properties[name]; !ok && !isTrue(schema.XPreserveUnknownFields) {
+			violations = append(violations, fmt.Sprintf("required field %s removed", formatSchemaPath(path+"."+name)))
+		}
+	}
+
+	var names []string
+	for name := range existingSchema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		existingProperty := existingSchema.Properties[name]
+		if property, ok := schema.Properties[name]; ok {
+			violations = append(violations, checkSchemaUpgrade(path+"."+name, &existingProperty, &property)...)
+		}
+	}
+	if existingSchema.Items != nil && existingSchema.Items.Schema != nil && schema.Items != nil && schema.Items.Schema != nil {
+		violations = append(violations, checkSchemaUpgrade(path+"[]", existingSchema.Items.Schema, schema.Items.Schema)...)
+	}
+	if existingSchema.AdditionalProperties != nil && existingSchema.AdditionalProperties.Schema != nil && schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		violations = append(violations, checkSchemaUpgrade(path+".*", existingSchema.AdditionalProperties.Schema, schema.AdditionalProperties.Schema)...)
+	}
+
+	return violations
+}
+
+func formatSchemaPath(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
@@ -22,7 +22,6 @@ import (
 	"fmt"
 	"math"
 	"reflect"
-	"strconv"
 	"strings"
 	"time"
 
@@ -43,6 +42,7 @@ import (
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/tools/record"
 	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
+	apiregistrationv1helper "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1/helper"
 	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -53,6 +53,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	"github.com/sap/component-operator-runtime/internal/backoff"
+	"github.com/sap/component-operator-runtime/internal/capabilities"
 	"github.com/sap/component-operator-runtime/pkg/manifests"
 	"github.com/sap/component-operator-runtime/pkg/types"
 )
@@ -71,12 +72,17 @@ const (
 	readyConditionReasonDeletionProcessing = "DeletionProcessing"
 )
 
+const (
+	componentReasonDeleteHooksSkipped = "DeleteHooksSkipped"
+)
+
 const (
 	objectReasonCreated     = "Created"
 	objectReasonUpdated     = "Updated"
 	objectReasonUpdateError = "UpdateError"
 	objectReasonDeleted     = "Deleted"
 	objectReasonDeleteError = "DeleteError"
+	objectReasonOrphaned    = "Orphaned"
 )
 
 const (
@@ -90,12 +96,42 @@ const (
 	updatePolicyRecreate = "recreate"
 )
 
+const (
+	deleteHookPreDelete  = "pre-delete"
+	deleteHookPostDelete = "post-delete"
+)
+
+const (
+	deleteHookPurgePolicyOnSuccess = "on-success"
+	deleteHookPurgePolicyOnFailure = "on-failure"
+)
+
+// maximum number of blocking instances reported when deletion of a managed type is blocked
+const maxReportedInstances = 5
+
+// time (since deletion of the component started) after which delete hooks are skipped if they cannot be rendered
+const deleteHooksRenderTimeout = 10 * time.Minute
+
 const (
 	scopeUnknown = iota
 	scopeNamespaced
 	scopeCluster
 )
 
+// NamespacePolicy defines how the reconciler deals with namespaces of dependent objects
+// which do not exist, and are not part of the rendered manifests.
+type NamespacePolicy string
+
+const (
+	// Never create missing namespaces; applying dependent objects to non-existing namespaces will fail.
+	NamespacePolicyNever NamespacePolicy = "Never"
+	// Create missing namespaces, but do not add them to the inventory; this is the default.
+	NamespacePolicyCreateUnmanaged NamespacePolicy = "CreateUnmanaged"
+	// Create missing namespaces, and add them to the inventory (such as other dependent objects);
+	// these namespaces will be deleted once they are no longer needed, but only if they are empty.
+	NamespacePolicyCreateManaged NamespacePolicy = "CreateManaged"
+)
+
 // HookFunc is the function signature that can be used to
 // establish callbacks at certain points in the reconciliation logic.
 // Hooks will be passed the current (potentially unsaved) state of the component.
@@ -103,27 +139,55 @@ const (
 // has been successful.
 type HookFunc[T Component] func(ctx context.Context, client client.Client, component T) error
 
+// DeletionGuard allows to block the deletion of components (and therefore of their dependent objects),
+// in addition to the built-in checks done by the reconciler (which prevent deletion as long as foreign instances of managed types exist).
+type DeletionGuard[T Component] interface {
+	// Check whether the given component (which is in deletion) may be deleted.
+	// If deletion shall be blocked, a non-empty, human-readable reason must be returned.
+	CheckDeletion(ctx context.Context, client client.Client, component T) (string, error)
+}
+
+// DeletionGuardFunc is an adapter allowing to use ordinary functions as DeletionGuard.
+type DeletionGuardFunc[T Component] func(ctx context.Context, client client.Client, component T) (string, error)
+
+// Check whether the given component may be deleted; calls f(ctx, client, component).
+func (f DeletionGuardFunc[T]) CheckDeletion(ctx context.Context, client client.Client, component T) (string, error) {
+	return f(ctx, client, component)
+}
+
 // Reconciler provides the implementation of controller-runtime's Reconciler interface, for a given Component type T.
 type Reconciler[T Component] struct {
-	name                         string
-	client                       client.Client
-	discoveryClient              discovery.DiscoveryInterface
-	recorder                     record.EventRecorder
-	scheme                       *runtime.Scheme
-	resourceGenerator            manifests.Generator
-	backoff                      *backoff.Backoff
-	postReadHooks                []HookFunc[T]
-	preReconcileHooks            []HookFunc[T]
-	postReconcileHooks           []HookFunc[T]
-	preDeleteHooks               []HookFunc[T]
-	postDeleteHooks              []HookFunc[T]
-	labelKeyOwnerId              string
-	annotationKeyDigest          string
-	annotationKeyReconcilePolicy string
-	annotationKeyUpdatePolicy    string
-	annotationKeyOrder           string
-	annotationKeyPurgeOrder      string
-	annotationKeyOwnerId         string
+	name                               string
+	client                             client.Client
+	apiReader                          client.Reader
+	discoveryClient                    discovery.DiscoveryInterface
+	recorder                           record.EventRecorder
+	scheme                             *runtime.Scheme
+	resourceGenerator                  manifests.Generator
+	backoff                            *backoff.Backoff
+	postReadHooks                      []HookFunc[T]
+	preReconcileHooks                  []HookFunc[T]
+	postReconcileHooks                 []HookFunc[T]
+	preDeleteHooks                     []HookFunc[T]
+	postDeleteHooks                    []HookFunc[T]
+	deletionGuards                     []DeletionGuard[T]
+	applyPriorities                    map[schema.GroupKind]int
+	deletePriorities                   map[schema.GroupKind]int
+	namespacePolicy                    NamespacePolicy
+	namespaceLabels                    map[string]string
+	namespaceAnnotations               map[string]string
+	labelKeyOwnerId                    string
+	annotationKeyDigest                string
+	annotationKeyReconcilePolicy       string
+	annotationKeyUpdatePolicy          string
+	annotationKeyOrder                 string
+	annotationKeyPurgeOrder            string
+	annotationKeyDeleteHook            string
+	annotationKeyDeleteHookPurgePolicy string
+	annotationKeyOwnerId               string
+	annotationKeyImplicitNamespace     string
+	annotationKeyForceDelete           string
+	annotationKeyForceUpgrade          string
 }
 
 // Create a new Reconciler. Here:
@@ -133,23 +197,34 @@ type Reconciler[T Component] struct {
 //   - discoveryClient should be a discovery client for the current cluster
 //   - scheme is required to recognize the core group (corev1), the api group containing T, and apiextensionsv1 and apiregistrationv1;
 //     in addition, scheme must know about all concrete (i.e. non-unstructured) types returned by the given resource generator
-//   - resourceGenerator must be an implementation of the manifests.Generator interface.
+//   - resourceGenerator must be an implementation of the manifests.Generator interface; if it implements manifests.GeneratorWithContext,
+//     GenerateWithContext() is called instead of Generate(), passing the reconciler's context, and reconcile information
+//     (a copy of the reconciled component, the reconciler's client, and release information); accordingly, if it implements
+//     manifests.NotesGeneratorWithContext, GenerateNotesWithContext() is called instead of GenerateNotes().
 func NewReconciler[T Component](name string, client client.Client, discoveryClient discovery.DiscoveryInterface, recorder record.EventRecorder, scheme *runtime.Scheme, resourceGenerator manifests.Generator) *Reconciler[T] {
 	return &Reconciler[T]{
-		name:                         name,
-		client:                       client,
-		discoveryClient:              discoveryClient,
-		recorder:                     recorder,
-		scheme:                       scheme,
-		resourceGenerator:            resourceGenerator,
-		backoff:                      backoff.NewBackoff(5 * time.Second),
-		labelKeyOwnerId:              name + "/owner-id",
-		annotationKeyDigest:          name + "/digest",
-		annotationKeyReconcilePolicy: name + "/reconcile-policy",
-		annotationKeyUpdatePolicy:    name + "/update-policy",
-		annotationKeyOrder:           name + "/order",
-		annotationKeyPurgeOrder:      name + "/purge-order",
-		annotationKeyOwnerId:         name + "/owner-id",
+		name:                               name,
+		client:                             client,
+		discoveryClient:                    discoveryClient,
+		recorder:                           recorder,
+		scheme:                             scheme,
+		resourceGenerator:                  resourceGenerator,
+		backoff:                            backoff.NewBackoff(5 * time.Second),
+		applyPriorities:                    defaultApplyPriorities(),
+		deletePriorities:                   defaultDeletePriorities(),
+		namespacePolicy:                    NamespacePolicyCreateUnmanaged,
+		labelKeyOwnerId:                    name + "/owner-id",
+		annotationKeyDigest:                name + "/digest",
+		annotationKeyReconcilePolicy:       name + "/reconcile-policy",
+		annotationKeyUpdatePolicy:          name + "/update-policy",
+		annotationKeyOrder:                 name + "/order",
+		annotationKeyPurgeOrder:            name + "/purge-order",
+		annotationKeyDeleteHook:            name + "/delete-hook",
+		annotationKeyDeleteHookPurgePolicy: name + "/delete-hook-purge-policy",
+		annotationKeyOwnerId:               name + "/owner-id",
+		annotationKeyImplicitNamespace:     name + "/implicit-namespace",
+		annotationKeyForceDelete:           name + "/force-delete",
+		annotationKeyForceUpgrade:          name + "/force-upgrade",
 	}
 }
 
@@ -191,6 +266,10 @@ func (r *Reconciler[T]) Reconcile(ctx context.Context, req ctrl.Request) (result
 		} else {
 			r.recorder.Event(component, corev1.EventTypeNormal, reason, message)
 		}
+		// invalidate cached cluster capabilities if managed custom resource definitions or api services changed
+		if apiExtensionsChanged(savedStatus.Inventory, status.Inventory) {
+			capabilities.Invalidate()
+		}
 		if skipStatusUpdate {
 			return
 		}
@@ -251,6 +330,11 @@ func (r *Reconciler[T]) Reconcile(ctx context.Context, req ctrl.Request) (result
 						return ctrl.Result{}, errors.Wrapf(err, "error running post-reconcile hook (%d)", hookOrder)
 					}
 				}
+				notes, err := r.renderNotes(ctx, component)
+				if err != nil {
+					return ctrl.Result{}, errors.Wrap(err, "error rendering notes")
+				}
+				status.Notes = notes
 				log.V(1).Info("all dependent resources successfully reconciled")
 				status.SetState(StateReady, readyConditionReasonReady, "Dependent resources successfully reconciled")
 				status.AppliedGeneration = component.GetGeneration()
@@ -364,8 +448,64 @@ func (r *Reconciler[T]) WithPostDeleteHook(hook HookFunc[T]) *Reconciler[T] {
 	return r
 }
 
+// Register deletion guard with reconciler.
+// Deletion guards will be called if the reconciled component is in deletion (has a deletionTimestamp set), before anything is deleted;
+// if one or more guards return a reason, deletion will be blocked, and the reasons will be reported in the component's status.
+// Deletion guards can be bypassed by setting the annotation <name>/force-delete: "true" on the component (where <name> is the name of the reconciler).
+func (r *Reconciler[T]) WithDeletionGuard(guard DeletionGuard[T]) *Reconciler[T] {
+	r.deletionGuards = append(r.deletionGuards, guard)
+	return r
+}
+
+// Set apply priority for the given kind, overriding the default priority of that kind (if any).
+// Within the same order (as defined by the order annotation), dependent objects are applied by ascending priority.
+// Kinds without explicitly set priority have priority 0.
+// By default, namespaces, webhook configurations, custom resource definitions, configmaps, secrets and RBAC objects
+// have negative priorities, and api services have a positive priority.
+func (r *Reconciler[T]) WithApplyPriority(groupKind schema.GroupKind, priority int) *Reconciler[T] {
+	r.applyPriorities[groupKind] = priority
+	return r
+}
+
+// Set delete priority for the given kind, overriding the default priority of that kind (if any).
+// Dependent objects are deleted by ascending priority. Kinds without explicitly set priority have priority 0.
+// By default, custom resource definitions and api services have negative priorities, and webhook configurations,
+// services, configmaps, secrets and namespaces have positive priorities.
+func (r *Reconciler[T]) WithDeletePriority(groupKind schema.GroupKind, priority int) *Reconciler[T] {
+	r.deletePriorities[groupKind] = priority
+	return r
+}
+
+// Set policy for namespaces of dependent objects which do not exist, and are not part of the rendered manifests.
+// The default policy is NamespacePolicyCreateUnmanaged.
+// Panics if the given policy is not one of the defined policies.
+func (r *Reconciler[T]) WithNamespacePolicy(policy NamespacePolicy) *Reconciler[T] {
+	switch policy {
+	case NamespacePolicyNever, NamespacePolicyCreateUnmanaged, NamespacePolicyCreateManaged:
+	default:
+		panic(fmt.Sprintf("invalid namespace policy: %s", policy))
+	}
+	r.namespacePolicy = policy
+	return r
+}
+
+// Set labels to be added to namespaces created by the reconciler (according to the namespace policy).
+// Useful for example to define pod security standards for the created namespaces.
+func (r *Reconciler[T]) WithNamespaceLabels(labels map[string]string) *Reconciler[T] {
+	r.namespaceLabels = labels
+	return r
+}
+
+// Set annotations to be added to namespaces created by the reconciler (according to the namespace policy).
+func (r *Reconciler[T]) WithNamespaceAnnotations(annotations map[string]string) *Reconciler[T] {
+	r.namespaceAnnotations = annotations
+	return r
+}
+
 // Register the reconciler with a given controller-runtime Manager.
+// The manager's (uncached) API reader is used to check whether namespaces are empty.
 func (r *Reconciler[T]) SetupWithManager(mgr ctrl.Manager) error {
+	r.apiReader = mgr.GetAPIReader()
 	component := newComponent[T]()
 	return ctrl.NewControllerManagedBy(mgr).
 		For(component).
@@ -375,131 +515,43 @@ func (r *Reconciler[T]) SetupWithManager(mgr ctrl.Manager) error {
 }
 
 func (r *Reconciler[T]) reconcileDependentResources(ctx context.Context, component Component) (bool, error) {
-	namespace := component.GetDeploymentNamespace()
-	name := component.GetDeploymentName()
+	log := log.FromContext(ctx)
 	ownerId := component.GetNamespace() + "/" + component.GetName()
 	status := component.GetStatus()
 
 	// render manifests
-	objects, err := r.resourceGenerator.Generate(namespace, name, component.GetSpec())
+	objects, err := r.renderObjects(ctx, component)
 	if err != nil {
-		return false, errors.Wrap(err, "error rendering manifests")
-	}
-
-	// normalize objects; that means:
-	// - check that unstructured objects have valid type information set, and convert them to their concrete type if known to the scheme
-	// - check that non-unstructured types are known to the scheme, and validate/set their type information
-	normalizedObjects := make([]client.Object, len(objects))
-	for i, object := range objects {
-		gvk := object.GetObjectKind().GroupVersionKind()
-		if unstructuredObject, ok := object.(*unstructured.Unstructured); ok {
-			if gvk.Version == "" || gvk.Kind == "" {
-				return false, fmt.Errorf("unstructured object %s is missing type information", types.ObjectKeyToString(object))
-			}
-			if r.scheme.Recognizes(gvk) {
-				typedObject, err := r.scheme.New(gvk)
-				if err != nil {
-					return false, errors.Wrapf(err, "error instantiating type for object %s", types.ObjectKeyToString(object))
-				}
-				if typedObject, ok := typedObject.(client.Object); ok {
-					if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredObject.Object, typedObject); err != nil {
-						return false, errors.Wrapf(err, "error converting object %s", types.ObjectKeyToString(object))
-					}
-					normalizedObjects[i] = typedObject
-				} else {
-					return false, errors.Wrapf(err, "error instantiating type for object %s", types.ObjectKeyToString(object))
-				}
-			} else if isCrd(object) || isApiService(object) {
-				return false, fmt.Errorf("scheme does not recognize type of object %s", types.ObjectKeyToString(object))
-			} else {
-				normalizedObjects[i] = object
-			}
-		} else {
-			_gvk, err := apiutil.GVKForObject(object, r.scheme)
-			if err != nil {
-				return false, errors.Wrapf(err, "error retrieving scheme type information for object %s", types.ObjectKeyToString(object))
-			}
-			if gvk.Version == "" || gvk.Kind == "" {
-				object.GetObjectKind().SetGroupVersionKind(_gvk)
-			} else if gvk != _gvk {
-				return false, fmt.Errorf("object %s specifies inconsistent type information (expected: %s)", types.ObjectKeyToString(object), _gvk)
-			}
-			normalizedObjects[i] = object
-		}
+		return false, err
 	}
-	objects = normalizedObjects
 
-	// validate type and set namespace for namespaced objects which have no namespace set
+	// skip delete hooks (they will only be applied when the component is deleted)
+	var nonHookObjects []client.Object
 	for _, object := range objects {
-		// note: due to the normalization done before, every object will now have a valid object kind set
-		gvk := object.GetObjectKind().GroupVersionKind()
-
-		scope := scopeUnknown
-		restMapping, err := r.client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
-		if err == nil {
-			scope = scopeFromRestMapping(restMapping)
-		} else if !meta.IsNoMatchError(err) {
-			return false, errors.Wrapf(err, "error getting rest mapping for object %s", types.ObjectKeyToString(object))
-		}
-		for _, crd := range getCrds(objects) {
-			if crd.Spec.Group == gvk.Group && crd.Spec.Names.Kind == gvk.Kind {
-				scope = scopeFromCrd(crd)
-				err = nil
-				break
-			}
-		}
-		for _, apiService := range getApiServices(objects) {
-			if apiService.Spec.Group == gvk.Group && apiService.Spec.Version == gvk.Version {
-				err = nil
-				break
-			}
-		}
-		if err != nil {
-			return false, errors.Wrapf(err, "error getting rest mapping for object %s", types.ObjectKeyToString(object))
-		}
-
-		if object.GetNamespace() == "" && scope == scopeNamespaced {
-			object.SetNamespace(namespace)
+		if r.getDeleteHook(object) == "" {
+			nonHookObjects = append(nonHookObjects, object)
 		}
 	}
-
-	// validate order annotations and define getter functions for later usage
-	getAnnotationInt := func(obj client.Object, key string, minValue int, maxValue int, defaultValue int) (int, error) {
-		if value, ok := obj.GetAnnotations()[key]; ok {
-			value, err := strconv.Atoi(value)
-			if err != nil {
-				return 0, err
-			}
-			if value < minValue || value > maxValue {
-				return 0, fmt.Errorf("value %d not in allowed range [%d,%d]", value, minValue, maxValue)
+	objects = nonHookObjects
+
+	// add missing namespaces to the target objects (if they shall be managed);
+	// note: existing namespaces which are not yet part of the inventory will not be touched
+	if r.namespacePolicy == NamespacePolicyCreateManaged {
+		for _, namespace := range findMissingNamespaces(objects) {
+			object := r.newNamespace(namespace)
+			if getItem(status.Inventory, object) == nil {
+				existingObject, err := r.readObject(ctx, object)
+				if err != nil {
+					return false, errors.Wrapf(err, "error reading namespace %s", namespace)
+				}
+				if existingObject != nil {
+					continue
+				}
 			}
-			return value, nil
-		} else {
-			return defaultValue, nil
-		}
-	}
-	for _, object := range objects {
-		if _, err := getAnnotationInt(object, r.annotationKeyOrder, math.MinInt16, math.MaxInt16, 0); err != nil {
-			return false, errors.Wrapf(err, "invalid value for annotation %s", r.annotationKeyOrder)
-		}
-		if _, err := getAnnotationInt(object, r.annotationKeyPurgeOrder, math.MinInt16, math.MaxInt16, math.MaxInt); err != nil {
-			return false, errors.Wrapf(err, "invalid value for annotation %s", r.annotationKeyPurgeOrder)
+			setAnnotation(object, r.annotationKeyImplicitNamespace, "true")
+			objects = append(objects, object)
 		}
 	}
-	getOrder := func(object client.Object) int {
-		order, err := getAnnotationInt(object, r.annotationKeyOrder, math.MinInt16, math.MaxInt16, 0)
-		if err != nil {
-			panic("this cannot happen")
-		}
-		return order
-	}
-	getPurgeOrder := func(object client.Object) int {
-		order, err := getAnnotationInt(object, r.annotationKeyPurgeOrder, math.MinInt16, math.MaxInt16, math.MaxInt)
-		if err != nil {
-			panic("this cannot happen")
-		}
-		return order
-	}
 
 	// add/update inventory with target objects
 	numAdded := 0
@@ -586,7 +638,7 @@ func (r *Reconciler[T]) reconcileDependentResources(ctx context.Context, compone
 	// trigger another reconcile
 	if numAdded > 0 {
 		// put inventory into right order for future deletion
-		status.Inventory = sortObjectsForDelete(status.Inventory)
+		status.Inventory = sortObjectsForDelete(status.Inventory, r.deletePriorities)
 		return false, nil
 	}
 
@@ -603,6 +655,16 @@ func (r *Reconciler[T]) reconcileDependentResources(ctx context.Context, compone
 		}
 	}
 
+	// count non-namespace objects which are about to be deleted
+	numNonNamespacesToBeDeleted := 0
+	for _, item := range status.Inventory {
+		if item.Phase == PhaseScheduledForDeletion || item.Phase == PhaseScheduledForCompletion || item.Phase == PhaseDeleting || item.Phase == PhaseCompleting {
+			if !isNamespace(item) {
+				numNonNamespacesToBeDeleted++
+			}
+		}
+	}
+
 	// delete redundant objects and maintain inventory
 	numToBeDeleted := 0
 	var inventory []*InventoryItem
@@ -616,6 +678,29 @@ func (r *Reconciler[T]) reconcileDependentResources(ctx context.Context, compone
 
 			switch item.Phase {
 			case PhaseScheduledForDeletion:
+				if r.isImplicitNamespace(existingObject) {
+					// implicitly created namespaces are deleted last, and only if they are empty; otherwise they are orphaned
+					if numNonNamespacesToBeDeleted > 0 {
+						numToBeDeleted++
+						break
+					}
+					empty, err := r.isNamespaceEmpty(ctx, item.GetName(), nil)
+					if err != nil {
+						if !discovery.IsGroupDiscoveryFailedError(err) {
+							return false, errors.Wrapf(err, "error checking whether namespace %s is empty", item.GetName())
+						}
+						// some api groups are currently unavailable; retry later
+						log.V(1).Info("unable to check whether namespace is empty; retrying", "namespace", item.GetName(), "error", err.Error())
+						numToBeDeleted++
+						break
+					}
+					if !empty {
+						if err := r.orphanNamespace(ctx, existingObject); err != nil {
+							return false, errors.Wrapf(err, "error orphaning namespace %s", item.GetName())
+						}
+						continue
+					}
+				}
 				if numManagedToBeDeleted == 0 || r.isManaged(item, component) {
 					// note: here is a theoretical risk that we delete an existing foreign object, because informers are not yet synced
 					// however not sending the delete request is also not an option, because this might lead to orphaned own dependents
@@ -669,21 +754,22 @@ func (r *Reconciler[T]) reconcileDependentResources(ctx context.Context, compone
 	// note: after this point, PhaseScheduledForDeletion, PhaseScheduledForCompletion, PhaseDeleting, PhaseCompleting cannot occur anymore in status.Inventory
 	// in other words: status.Inventory and objects contains the same resources
 
-	// create missing namespaces
-	// TODO: make this more configurable
-	for _, namespace := range findMissingNamespaces(objects) {
-		if err := r.client.Get(ctx, apitypes.NamespacedName{Name: namespace}, &corev1.Namespace{}); err != nil {
-			if !apierrors.IsNotFound(err) {
-				return false, errors.Wrapf(err, "error reading namespace %s", namespace)
-			}
-			if err := r.client.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}); err != nil {
-				return false, errors.Wrapf(err, "error creating namespace %s", namespace)
+	// create missing namespaces (if they shall be created without being managed)
+	if r.namespacePolicy == NamespacePolicyCreateUnmanaged {
+		for _, namespace := range findMissingNamespaces(objects) {
+			if err := r.client.Get(ctx, apitypes.NamespacedName{Name: namespace}, &corev1.Namespace{}); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return false, errors.Wrapf(err, "error reading namespace %s", namespace)
+				}
+				if err := r.client.Create(ctx, r.newNamespace(namespace)); err != nil {
+					return false, errors.Wrapf(err, "error creating namespace %s", namespace)
+				}
 			}
 		}
 	}
 
 	// put objects into right order for applying
-	objects = sortObjectsForApply(objects, getOrder)
+	objects = sortObjectsForApply(objects, r.getOrder, r.applyPriorities)
 
 	// apply new objects and maintain inventory
 	numUnready := 0
@@ -700,16 +786,16 @@ func (r *Reconciler[T]) reconcileDependentResources(ctx context.Context, compone
 		}
 
 		// retrieve object order
-		order := getOrder(object)
+		order := r.getOrder(object)
 
 		// retrieve inventory item corresponding to this object
 		item := mustGetItem(status.Inventory, object)
 
 		// if this is the first object of an order, then
 		// count instances of managed types in this order which are about to be applied
-		if k == 0 || getOrder(objects[k-1]) < order {
+		if k == 0 || r.getOrder(objects[k-1]) < order {
 			numNotManagedToBeApplied = 0
-			for j := k; j < len(objects) && getOrder(objects[j]) == order; j++ {
+			for j := k; j < len(objects) && r.getOrder(objects[j]) == order; j++ {
 				_object := objects[j]
 				_item := mustGetItem(status.Inventory, _object)
 				if _item.Phase != PhaseReady && _item.Phase != PhaseCompleted && !r.isManaged(_object, component) {
@@ -740,6 +826,11 @@ func (r *Reconciler[T]) reconcileDependentResources(ctx context.Context, compone
 					item.Status = kstatus.InProgressStatus.String()
 					numUnready++
 				} else if existingObject.GetAnnotations()[r.annotationKeyDigest] != item.Digest {
+					if isCrd(object) {
+						if err := r.validateCrdUpgrade(object, existingObject); err != nil {
+							return false, errors.Wrapf(err, "error validating update of object %s", item)
+						}
+					}
 					switch updatePolicy {
 					case updatePolicyDefault:
 						if err := r.updateObject(ctx, object, existingObject); err != nil {
@@ -777,13 +868,13 @@ func (r *Reconciler[T]) reconcileDependentResources(ctx context.Context, compone
 		// if this is the last object of an order, then
 		// - if everything so far is ready, trigger due completions and trigger another reconcile if any completion was triggered
 		// - otherwise trigger another reconcile
-		if k == len(objects)-1 || getOrder(objects[k+1]) > order {
+		if k == len(objects)-1 || r.getOrder(objects[k+1]) > order {
 			if numUnready == 0 {
 				numPurged := 0
 				for j := 0; j <= k; j++ {
 					_object := objects[j]
 					_item := mustGetItem(status.Inventory, _object)
-					_purgeOrder := getPurgeOrder(_object)
+					_purgeOrder := r.getPurgeOrder(_object)
 					if (k == len(objects)-1 && _purgeOrder < math.MaxInt || _purgeOrder <= order) && _item.Phase != PhaseCompleted {
 						_item.Phase = PhaseScheduledForCompletion
 						numPurged++
@@ -801,9 +892,188 @@ func (r *Reconciler[T]) reconcileDependentResources(ctx context.Context, compone
 	return numUnready == 0, nil
 }
 
+// render notes (if the resource generator implements manifests.NotesGenerator), using the same context and reconcile information as renderObjects()
+func (r *Reconciler[T]) renderNotes(ctx context.Context, component Component) (string, error) {
+	namespace := component.GetDeploymentNamespace()
+	name := component.GetDeploymentName()
+
+	if generator, ok := r.resourceGenerator.(manifests.NotesGeneratorWithContext); ok {
+		return generator.GenerateNotesWithContext(ctx, r.getReconcileInfo(component), namespace, name, component.GetSpec())
+	} else if generator, ok := r.resourceGenerator.(manifests.NotesGenerator); ok {
+		return generator.GenerateNotes(namespace, name, component.GetSpec(), getReleaseInfo(component))
+	}
+	return "", nil
+}
+
+// render manifests, normalize the rendered objects, and validate their annotations
+func (r *Reconciler[T]) renderObjects(ctx context.Context, component Component) ([]client.Object, error) {
+	namespace := component.GetDeploymentNamespace()
+	name := component.GetDeploymentName()
+
+	// render manifests
+	var objects []client.Object
+	var err error
+	if generator, ok := r.resourceGenerator.(manifests.GeneratorWithContext); ok {
+		objects, err = generator.GenerateWithContext(ctx, r.getReconcileInfo(component), namespace, name, component.GetSpec())
+	} else if generator, ok := r.resourceGenerator.(manifests.ReleaseAwareGenerator); ok {
+		objects, err = generator.GenerateRelease(namespace, name, component.GetSpec(), getReleaseInfo(component))
+	} else {
+		objects, err = r.resourceGenerator.Generate(namespace, name, component.GetSpec())
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error rendering manifests")
+	}
+
+	// normalize objects; that means:
+	// - check that unstructured objects have valid type information set, and convert them to their concrete type if known to the scheme
+	// - check that non-unstructured types are known to the scheme, and validate/set their type information
+	normalizedObjects := make([]client.Object, len(objects))
+	for i, object := range objects {
+		gvk := object.GetObjectKind().GroupVersionKind()
+		if unstructuredObject, ok := object.(*unstructured.Unstructured); ok {
+			if gvk.Version == "" || gvk.Kind == "" {
+				return nil, fmt.Errorf("unstructured object %s is missing type information", types.ObjectKeyToString(object))
+			}
+			if r.scheme.Recognizes(gvk) {
+				typedObject, err := r.scheme.New(gvk)
+				if err != nil {
+					return nil, errors.Wrapf(err, "error instantiating type for object %s", types.ObjectKeyToString(object))
+				}
+				if typedObject, ok := typedObject.(client.Object); ok {
+					if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredObject.Object, typedObject); err != nil {
+						return nil, errors.Wrapf(err, "error converting object %s", types.ObjectKeyToString(object))
+					}
+					normalizedObjects[i] = typedObject
+				} else {
+					return nil, errors.Wrapf(err, "error instantiating type for object %s", types.ObjectKeyToString(object))
+				}
+			} else if isCrd(object) || isApiService(object) {
+				return nil, fmt.Errorf("scheme does not recognize type of object %s", types.ObjectKeyToString(object))
+			} else {
+				normalizedObjects[i] = object
+			}
+		} else {
+			_gvk, err := apiutil.GVKForObject(object, r.scheme)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error retrieving scheme type information for object %s", types.ObjectKeyToString(object))
+			}
+			if gvk.Version == "" || gvk.Kind == "" {
+				object.GetObjectKind().SetGroupVersionKind(_gvk)
+			} else if gvk != _gvk {
+				return nil, fmt.Errorf("object %s specifies inconsistent type information (expected: %s)", types.ObjectKeyToString(object), _gvk)
+			}
+			normalizedObjects[i] = object
+		}
+	}
+	objects = normalizedObjects
+
+	// validate type and set namespace for namespaced objects which have no namespace set
+	for _, object := range objects {
+		// note: due to the normalization done before, every object will now have a valid object kind set
+		gvk := object.GetObjectKind().GroupVersionKind()
+
+		scope := scopeUnknown
+		restMapping, err := r.client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err == nil {
+			scope = scopeFromRestMapping(restMapping)
+		} else if !meta.IsNoMatchError(err) {
+			return nil, errors.Wrapf(err, "error getting rest mapping for object %s", types.ObjectKeyToString(object))
+		}
+		for _, crd := range getCrds(objects) {
+			if crd.Spec.Group == gvk.Group && crd.Spec.Names.Kind == gvk.Kind {
+				scope = scopeFromCrd(crd)
+				err = nil
+				break
+			}
+		}
+		for _, apiService := range getApiServices(objects) {
+			if apiService.Spec.Group == gvk.Group && apiService.Spec.Version == gvk.Version {
+				err = nil
+				break
+			}
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "error getting rest mapping for object %s", types.ObjectKeyToString(object))
+		}
+
+		if object.GetNamespace() == "" && scope == scopeNamespaced {
+			object.SetNamespace(namespace)
+		}
+	}
+
+	// validate annotations
+	for _, object := range objects {
+		if _, err := getAnnotationInt(object, r.annotationKeyOrder, math.MinInt16, math.MaxInt16, 0); err != nil {
+			return nil, errors.Wrapf(err, "invalid value for annotation %s", r.annotationKeyOrder)
+		}
+		if _, err := getAnnotationInt(object, r.annotationKeyPurgeOrder, math.MinInt16, math.MaxInt16, math.MaxInt); err != nil {
+			return nil, errors.Wrapf(err, "invalid value for annotation %s", r.annotationKeyPurgeOrder)
+		}
+		if deleteHook, ok := object.GetAnnotations()[r.annotationKeyDeleteHook]; ok && deleteHook != deleteHookPreDelete && deleteHook != deleteHookPostDelete {
+			return nil, fmt.Errorf("invalid value for annotation %s: %s", r.annotationKeyDeleteHook, deleteHook)
+		}
+		if value, ok := object.GetAnnotations()[r.annotationKeyDeleteHookPurgePolicy]; ok {
+			for _, purgePolicy := range strings.Split(value, ",") {
+				if purgePolicy != deleteHookPurgePolicyOnSuccess && purgePolicy != deleteHookPurgePolicyOnFailure {
+					return nil, fmt.Errorf("invalid value for annotation %s: %s", r.annotationKeyDeleteHookPurgePolicy, value)
+				}
+			}
+		}
+	}
+
+	return objects, nil
+}
+
+// return the reconcile information passed to context-aware generators;
+// note: the generator gets a copy of the component, such that it cannot modify the reconciled object
+func (r *Reconciler[T]) getReconcileInfo(component Component) manifests.ReconcileInfo {
+	release := getReleaseInfo(component)
+	return manifests.ReconcileInfo{
+		Component: component.DeepCopyObject().(Component),
+		Client:    r.client,
+		Release:   &release,
+	}
+}
+
 func (r *Reconciler[T]) deleteDependentResources(ctx context.Context, component Component) (bool, error) {
+	log := log.FromContext(ctx)
 	status := component.GetStatus()
 
+	// render delete hooks
+	// note: if rendering fails, it is retried (since the error might be transient, or delete hooks might still be running);
+	// only if nothing was deployed, if the error is permanent (that is, the component's parameters are invalid) and no delete hooks are in progress,
+	// or if rendering failed for longer than deleteHooksRenderTimeout, deletion proceeds without running delete hooks, since otherwise the component could not be deleted at all
+	var preDeleteHooks []client.Object
+	var postDeleteHooks []client.Object
+	objects, err := r.renderObjects(ctx, component)
+	if err != nil {
+		var validationErr *manifests.ParameterValidationError
+		permanent := errors.As(err, &validationErr) && !hasItemsInProgress(status.Inventory)
+		if len(status.Inventory) > 0 && !permanent && time.Since(component.GetDeletionTimestamp().Time) < deleteHooksRenderTimeout {
+			return false, errors.Wrap(err, "error rendering delete hooks")
+		}
+		log.V(1).Info("error rendering delete hooks; skipping delete hooks", "error", err.Error())
+		if len(status.Inventory) > 0 {
+			r.recorder.Eventf(component, corev1.EventTypeWarning, componentReasonDeleteHooksSkipped, "Error rendering delete hooks; deleting dependent objects without running delete hooks: %s", err)
+		}
+		objects = nil
+	}
+	for _, object := range objects {
+		switch r.getDeleteHook(object) {
+		case deleteHookPreDelete:
+			preDeleteHooks = append(preDeleteHooks, object)
+		case deleteHookPostDelete:
+			postDeleteHooks = append(postDeleteHooks, object)
+		}
+	}
+
+	// apply pre-delete hooks
+	if ok, err := r.applyDeleteHooks(ctx, component, preDeleteHooks); err != nil {
+		return false, errors.Wrap(err, "error applying pre-delete hooks")
+	} else if !ok {
+		return false, nil
+	}
+
 	// count instances of managed types
 	numManaged := 0
 	for _, item := range status.Inventory {
@@ -812,9 +1082,19 @@ func (r *Reconciler[T]) deleteDependentResources(ctx context.Context, component
 		}
 	}
 
-	// delete objects and maintain inventory
+	// delete objects (except delete hooks and implicitly created namespaces) and maintain inventory
+	// note: delete hooks are only deleted according to their purge policy (see applyDeleteHooks()), and kept in the inventory,
+	// such that they are not applied again
+	numToBeDeleted := 0
 	var inventory []*InventoryItem
+	var hookItems []*InventoryItem
 	for _, item := range status.Inventory {
+		if containsObject(preDeleteHooks, item) || containsObject(postDeleteHooks, item) {
+			inventory = append(inventory, item)
+			hookItems = append(hookItems, item)
+			continue
+		}
+
 		// fetch object (if existing)
 		existingObject, err := r.readObject(ctx, item)
 		if err != nil {
@@ -826,6 +1106,12 @@ func (r *Reconciler[T]) deleteDependentResources(ctx context.Context, component
 			continue
 		}
 
+		// implicitly created namespaces are deleted last (see below)
+		if r.isImplicitNamespace(existingObject) {
+			inventory = append(inventory, item)
+			continue
+		}
+
 		if numManaged == 0 || r.isManaged(item, component) {
 			// delete the object
 			// note: here is a theoretical risk that we delete an existing (foreign) object, because informers are not yet synced
@@ -836,15 +1122,206 @@ func (r *Reconciler[T]) deleteDependentResources(ctx context.Context, component
 			item.Phase = PhaseDeleting
 			item.Status = kstatus.TerminatingStatus.String()
 		}
+		numToBeDeleted++
+		inventory = append(inventory, item)
+	}
+	status.Inventory = inventory
+
+	// trigger another reconcile
+	if numToBeDeleted > 0 {
+		return false, nil
+	}
+
+	// apply post-delete hooks
+	if ok, err := r.applyDeleteHooks(ctx, component, postDeleteHooks); err != nil {
+		return false, errors.Wrap(err, "error applying post-delete hooks")
+	} else if !ok {
+		return false, nil
+	}
+
+	// delete implicitly created namespaces (only if they are empty, apart from delete hooks, which are deleted together with the namespace;
+	// otherwise they are orphaned) and maintain inventory
+	inventory = nil
+	for _, item := range status.Inventory {
+		if containsObject(preDeleteHooks, item) || containsObject(postDeleteHooks, item) {
+			inventory = append(inventory, item)
+			continue
+		}
+
+		// note: all remaining items are implicitly created namespaces
+		existingObject, err := r.readObject(ctx, item)
+		if err != nil {
+			return false, errors.Wrapf(err, "error reading object %s", item)
+		}
+		if existingObject == nil {
+			continue
+		}
+		if item.Phase != PhaseDeleting {
+			empty, err := r.isNamespaceEmpty(ctx, item.GetName(), hookItems)
+			if err != nil {
+				if !discovery.IsGroupDiscoveryFailedError(err) {
+					return false, errors.Wrapf(err, "error checking whether namespace %s is empty", item.GetName())
+				}
+				// some api groups are currently unavailable; retry later
+				log.V(1).Info("unable to check whether namespace is empty; retrying", "namespace", item.GetName(), "error", err.Error())
+				numToBeDeleted++
+				inventory = append(inventory, item)
+				continue
+			}
+			if !empty {
+				if err := r.orphanNamespace(ctx, existingObject); err != nil {
+					return false, errors.Wrapf(err, "error orphaning namespace %s", item.GetName())
+				}
+				continue
+			}
+			if err := r.deleteObject(ctx, item, existingObject); err != nil {
+				return false, errors.Wrapf(err, "error deleting object %s", item)
+			}
+			item.Phase = PhaseDeleting
+			item.Status = kstatus.TerminatingStatus.String()
+		}
+		numToBeDeleted++
 		inventory = append(inventory, item)
 	}
 	status.Inventory = inventory
 
-	return len(status.Inventory) == 0, nil
+	// trigger another reconcile
+	if numToBeDeleted > 0 {
+		return false, nil
+	}
+
+	// note: delete hooks which were not purged (and not deleted together with an implicitly created namespace) are orphaned (as with helm)
+	status.Inventory = nil
+
+	return true, nil
+}
+
+// apply given delete hooks order by order (and by priority within the same order), and wait until they are ready (or purged);
+// existing objects are recreated; returns true if all hooks are ready (or purged), and an error if a hook failed
+func (r *Reconciler[T]) applyDeleteHooks(ctx context.Context, component Component, objects []client.Object) (bool, error) {
+	ownerId := component.GetNamespace() + "/" + component.GetName()
+	status := component.GetStatus()
+
+	// add inventory items for delete hooks; if new items were added, trigger another reconcile
+	// (this ensures that the inventory is persisted before the hooks are applied)
+	numAdded := 0
+	for _, object := range objects {
+		if getItem(status.Inventory, object) != nil {
+			continue
+		}
+		raw, err := json.Marshal(object)
+		if err != nil {
+			return false, errors.Wrapf(err, "error serializing object %s", types.ObjectKeyToString(object))
+		}
+		gvk := object.GetObjectKind().GroupVersionKind()
+		status.Inventory = append(status.Inventory, &InventoryItem{
+			TypeInfo: TypeInfo{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind},
+			NameInfo: NameInfo{Namespace: object.GetNamespace(), Name: object.GetName()},
+			Digest:   sha256hash(raw),
+			Phase:    PhaseScheduledForApplication,
+			Status:   kstatus.InProgressStatus.String(),
+		})
+		numAdded++
+	}
+	if numAdded > 0 {
+		return false, nil
+	}
+
+	objects = sortObjectsForApply(objects, r.getOrder, r.applyPriorities)
+
+	numUnready := 0
+	for k, object := range objects {
+		order := r.getOrder(object)
+		item := mustGetItem(status.Inventory, object)
+		purgePolicies := strings.Split(object.GetAnnotations()[r.annotationKeyDeleteHookPurgePolicy], ",")
+
+		switch item.Phase {
+		case PhaseScheduledForApplication:
+			existingObject, err := r.readObject(ctx, item)
+			if err != nil {
+				return false, errors.Wrapf(err, "error reading object %s", item)
+			}
+			if existingObject == nil {
+				setLabel(object, r.labelKeyOwnerId, strings.Replace(ownerId, "/", "_", -1))
+				setAnnotation(object, r.annotationKeyOwnerId, ownerId)
+				setAnnotation(object, r.annotationKeyDigest, item.Digest)
+				if err := r.createObject(ctx, object); err != nil {
+					return false, errors.Wrapf(err, "error creating object %s", item)
+				}
+				item.Phase = PhaseCreating
+				item.Status = kstatus.InProgressStatus.String()
+			} else if existingObject.GetDeletionTimestamp().IsZero() {
+				// existing objects are recreated
+				if err := r.deleteObject(ctx, item, existingObject); err != nil {
+					return false, errors.Wrapf(err, "error deleting (while recreating) object %s", item)
+				}
+			}
+			numUnready++
+		case PhaseCreating:
+			existingObject, err := r.readObject(ctx, item)
+			if err != nil {
+				return false, errors.Wrapf(err, "error reading object %s", item)
+			}
+			if existingObject == nil {
+				// the object disappeared, so it will be recreated
+				item.Phase = PhaseScheduledForApplication
+				numUnready++
+				break
+			}
+			res, err := computeStatus(existingObject)
+			if err != nil {
+				return false, errors.Wrapf(err, "error checking status of object %s", item)
+			}
+			item.Status = res.Status.String()
+			switch res.Status {
+			case kstatus.CurrentStatus:
+				if slices.Contains(purgePolicies, deleteHookPurgePolicyOnSuccess) {
+					if err := r.deleteObject(ctx, item, existingObject); err != nil {
+						return false, errors.Wrapf(err, "error deleting object %s", item)
+					}
+					item.Phase = PhaseCompleting
+					item.Status = kstatus.TerminatingStatus.String()
+					numUnready++
+				} else {
+					item.Phase = PhaseReady
+				}
+			case kstatus.FailedStatus:
+				if slices.Contains(purgePolicies, deleteHookPurgePolicyOnFailure) {
+					// the object will be recreated in a subsequent reconcile
+					if err := r.deleteObject(ctx, item, existingObject); err != nil {
+						return false, errors.Wrapf(err, "error deleting object %s", item)
+					}
+					item.Phase = PhaseScheduledForApplication
+				}
+				return false, fmt.Errorf("delete hook %s failed", item)
+			default:
+				numUnready++
+			}
+		case PhaseCompleting:
+			existingObject, err := r.readObject(ctx, item)
+			if err != nil {
+				return false, errors.Wrapf(err, "error reading object %s", item)
+			}
+			if existingObject == nil {
+				item.Phase = PhaseCompleted
+				item.Status = ""
+			} else {
+				numUnready++
+			}
+		}
+
+		// if this is the last object of an order, and not everything so far is ready, trigger another reconcile
+		if (k == len(objects)-1 || r.getOrder(objects[k+1]) > order) && numUnready > 0 {
+			return false, nil
+		}
+	}
+
+	return true, nil
 }
 
 func (r *Reconciler[T]) deletionAllowed(ctx context.Context, component Component) (bool, string, error) {
 	status := component.GetStatus()
+	var reasons []string
 
 	for _, item := range status.Inventory {
 		switch {
@@ -857,12 +1334,12 @@ func (r *Reconciler[T]) deletionAllowed(ctx context.Context, component Component
 					return false, "", errors.Wrapf(err, "error retrieving crd %s", item.GetName())
 				}
 			}
-			used, err := r.isCrdUsed(ctx, crd, true)
+			instances, err := r.getCrdInstances(ctx, crd, true, maxReportedInstances+1)
 			if err != nil {
 				return false, "", errors.Wrapf(err, "error checking usage of crd %s", item.GetName())
 			}
-			if used {
-				return false, fmt.Sprintf("crd %s is still in use (instances exist)", item.GetName()), nil
+			if len(instances) > 0 {
+				reasons = append(reasons, fmt.Sprintf("crd %s is still in use (instances exist: %s)", item.GetName(), describeObjects(instances, maxReportedInstances)))
 			}
 		case isApiService(item):
 			apiService := &apiregistrationv1.APIService{}
@@ -873,20 +1350,164 @@ func (r *Reconciler[T]) deletionAllowed(ctx context.Context, component Component
 					return false, "", errors.Wrapf(err, "error retrieving api service %s", item.GetName())
 				}
 			}
-			used, err := r.isApiServiceUsed(ctx, apiService, true)
+			instances, err := r.getApiServiceInstances(ctx, apiService, true, maxReportedInstances+1)
 			if err != nil {
 				return false, "", errors.Wrapf(err, "error checking usage of api service %s", item.GetName())
 			}
-			if used {
-				// TODO: other than with CRDs it is not clear for which types there are instances existing
-				// we should improve the error message somehow
-				return false, fmt.Sprintf("api service %s is still in use (instances exist)", item.GetName()), nil
+			if len(instances) > 0 {
+				reasons = append(reasons, fmt.Sprintf("api service %s is still in use (instances exist: %s)", item.GetName(), describeObjects(instances, maxReportedInstances)))
+			}
+		}
+	}
+
+	// note: the built-in checks above cannot be bypassed, because deleting managed types would orphan (or even break) their foreign instances
+	if component.GetAnnotations()[r.annotationKeyForceDelete] != "true" {
+		for guardOrder, guard := range r.deletionGuards {
+			reason, err := guard.CheckDeletion(ctx, r.client, component.(T))
+			if err != nil {
+				return false, "", errors.Wrapf(err, "error running deletion guard (%d)", guardOrder)
+			}
+			if reason != "" {
+				reasons = append(reasons, reason)
 			}
 		}
 	}
+
+	if len(reasons) > 0 {
+		return false, strings.Join(reasons, "; "), nil
+	}
 	return true, "", nil
 }
 
+func (r *Reconciler[T]) getOrder(object client.Object) int {
+	order, err := getAnnotationInt(object, r.annotationKeyOrder, math.MinInt16, math.MaxInt16, 0)
+	if err != nil {
+		panic("this cannot happen")
+	}
+	return order
+}
+
+func (r *Reconciler[T]) getPurgeOrder(object client.Object) int {
+	order, err := getAnnotationInt(object, r.annotationKeyPurgeOrder, math.MinInt16, math.MaxInt16, math.MaxInt)
+	if err != nil {
+		panic("this cannot happen")
+	}
+	return order
+}
+
+func (r *Reconciler[T]) getDeleteHook(object client.Object) string {
+	return object.GetAnnotations()[r.annotationKeyDeleteHook]
+}
+
+func (r *Reconciler[T]) validateCrdUpgrade(object client.Object, existingObject *unstructured.Unstructured) error {
+	if object.GetAnnotations()[r.annotationKeyForceUpgrade] == "true" || existingObject.GetAnnotations()[r.annotationKeyForceUpgrade] == "true" {
+		return nil
+	}
+	crd, ok := object.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok {
+		panic("this cannot happen")
+	}
+	existingCrd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(existingObject.Object, existingCrd); err != nil {
+		return err
+	}
+	if violations := checkCrdUpgrade(existingCrd, crd); len(violations) > 0 {
+		return fmt.Errorf("incompatible changes to custom resource definition (%s); annotate the custom resource definition with %s: \"true\" to enforce the update", strings.Join(violations, "; "), r.annotationKeyForceUpgrade)
+	}
+	return nil
+}
+
+func (r *Reconciler[T]) newNamespace(name string) *corev1.Namespace {
+	namespace := &corev1.Namespace{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+	for key, value := range r.namespaceLabels {
+		setLabel(namespace, key, value)
+	}
+	for key, value := range r.namespaceAnnotations {
+		setAnnotation(namespace, key, value)
+	}
+	return namespace
+}
+
+func (r *Reconciler[T]) isImplicitNamespace(existingObject *unstructured.Unstructured) bool {
+	return existingObject != nil && isNamespace(existingObject) && existingObject.GetAnnotations()[r.annotationKeyImplicitNamespace] == "true"
+}
+
+// check whether the given namespace is empty (apart from boilerplate objects such as the default service account, and the given ignored objects);
+// if some api groups could not be discovered, and no objects were found in the other groups, the discovery error is returned
+// (which can be recognized by discovery.IsGroupDiscoveryFailedError())
+func (r *Reconciler[T]) isNamespaceEmpty(ctx context.Context, namespace string, ignoredObjects []*InventoryItem) (bool, error) {
+	resLists, discoveryErr := discovery.ServerPreferredNamespacedResources(r.discoveryClient)
+	if discoveryErr != nil && !discovery.IsGroupDiscoveryFailedError(discoveryErr) {
+		return false, discoveryErr
+	}
+	// note: an uncached reader is used (if available), and only metadata is retrieved, to avoid starting informers for all types
+	var reader client.Reader = r.client
+	if r.apiReader != nil {
+		reader = r.apiReader
+	}
+	for _, resList := range discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list"}}, resLists) {
+		gv, err := schema.ParseGroupVersion(resList.GroupVersion)
+		if err != nil {
+			return false, err
+		}
+		for _, res := range resList.APIResources {
+			gvk := gv.WithKind(res.Kind)
+			if isEvent(gvk.GroupKind()) {
+				continue
+			}
+			var ignoredNames []string
+			for _, object := range ignoredObjects {
+				if object.GetObjectKind().GroupVersionKind().GroupKind() == gvk.GroupKind() && object.GetNamespace() == namespace {
+					ignoredNames = append(ignoredNames, object.GetName())
+				}
+			}
+			// note: there is at most one boilerplate object per type, so it is sufficient to retrieve two objects (plus the ignored ones)
+			list := &metav1.PartialObjectMetadataList{}
+			list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+			if err := reader.List(ctx, list, client.InNamespace(namespace), client.Limit(int64(2+len(ignoredNames)))); err != nil {
+				if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+					continue
+				}
+				return false, err
+			}
+			for _, item := range list.Items {
+				if !isNamespaceBoilerplate(gvk.GroupKind(), item.GetName()) && !slices.Contains(ignoredNames, item.GetName()) {
+					return false, nil
+				}
+			}
+			if list.GetContinue() != "" {
+				return false, nil
+			}
+		}
+	}
+	if discoveryErr != nil {
+		return false, discoveryErr
+	}
+	return true, nil
+}
+
+// remove ownership information from the given (implicitly created) namespace, which is about to be dropped from the inventory
+// because it is not empty, and emit an according event
+func (r *Reconciler[T]) orphanNamespace(ctx context.Context, existingObject *unstructured.Unstructured) error {
+	object := existingObject.DeepCopy()
+	labels := object.GetLabels()
+	delete(labels, r.labelKeyOwnerId)
+	object.SetLabels(labels)
+	annotations := object.GetAnnotations()
+	delete(annotations, r.annotationKeyOwnerId)
+	delete(annotations, r.annotationKeyDigest)
+	delete(annotations, r.annotationKeyImplicitNamespace)
+	object.SetAnnotations(annotations)
+	if err := r.client.Patch(ctx, object, client.MergeFrom(existingObject)); err != nil {
+		return err
+	}
+	r.recorder.Event(existingObject, corev1.EventTypeWarning, objectReasonOrphaned, "Namespace is not empty; orphaning it instead of deleting it")
+	return nil
+}
+
 func (r *Reconciler[T]) readObject(ctx context.Context, key types.ObjectKey) (*unstructured.Unstructured, error) {
 	obj := &unstructured.Unstructured{}
 	obj.SetGroupVersionKind(key.GetObjectKind().GroupVersionKind())
@@ -973,11 +1594,11 @@ func (r *Reconciler[T]) deleteObject(ctx context.Context, key types.ObjectKey, e
 			if err := r.client.Get(ctx, apitypes.NamespacedName{Name: key.GetName()}, crd); err != nil {
 				return client.IgnoreNotFound(err)
 			}
-			used, err := r.isCrdUsed(ctx, crd, false)
+			instances, err := r.getCrdInstances(ctx, crd, false, 1)
 			if err != nil {
 				return err
 			}
-			if used {
+			if len(instances) > 0 {
 				return fmt.Errorf("error deleting custom resource definition %s, existing instances found", types.ObjectKeyToString(key))
 			}
 			if ok := controllerutil.RemoveFinalizer(crd, r.name); ok {
@@ -998,11 +1619,11 @@ func (r *Reconciler[T]) deleteObject(ctx context.Context, key types.ObjectKey, e
 			if err := r.client.Get(ctx, apitypes.NamespacedName{Name: key.GetName()}, apiService); err != nil {
 				return client.IgnoreNotFound(err)
 			}
-			used, err := r.isApiServiceUsed(ctx, apiService, false)
+			instances, err := r.getApiServiceInstances(ctx, apiService, false, 1)
 			if err != nil {
 				return err
 			}
-			if used {
+			if len(instances) > 0 {
 				return fmt.Errorf("error deleting api service %s, existing instances found", types.ObjectKeyToString(key))
 			}
 			if ok := controllerutil.RemoveFinalizer(apiService, r.name); ok {
@@ -1021,32 +1642,69 @@ func (r *Reconciler[T]) deleteObject(ctx context.Context, key types.ObjectKey, e
 	return nil
 }
 
-func (r *Reconciler[T]) isCrdUsed(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition, onlyForeign bool) (bool, error) {
-	gvk := schema.GroupVersionKind{
-		Group:   crd.Spec.Group,
-		Version: crd.Spec.Versions[0].Name,
-		Kind:    crd.Spec.Names.Kind,
-	}
-	list := &unstructured.UnstructuredList{}
-	list.SetGroupVersionKind(gvk)
+// return (up to limit) instances of the given crd; all served versions are checked;
+// if onlyForeign is true, instances owned by the owner of the crd will be ignored
+func (r *Reconciler[T]) getCrdInstances(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition, onlyForeign bool, limit int) ([]types.ObjectKey, error) {
 	labelSelector := labels.Everything()
 	if onlyForeign {
 		labelSelector = mustParseLabelSelector(r.labelKeyOwnerId + "!=" + crd.Labels[r.labelKeyOwnerId])
 	}
-	if err := r.client.List(ctx, list, &client.ListOptions{LabelSelector: labelSelector, Limit: 1}); err != nil {
-		return false, err
+	var instances []types.ObjectKey
+	for _, version := range crd.Spec.Versions {
+		if !version.Served {
+			continue
+		}
+		gvk := schema.GroupVersionKind{
+			Group:   crd.Spec.Group,
+			Version: version.Name,
+			Kind:    crd.Spec.Names.Kind,
+		}
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk)
+		if err := r.client.List(ctx, list, &client.ListOptions{LabelSelector: labelSelector, Limit: int64(limit)}); err != nil {
+			return nil, err
+		}
+		// note: the same instances are usually returned for every served version, so duplicates have to be eliminated
+		for i := 0; i < len(list.Items) && len(instances) < limit; i++ {
+			instance := &list.Items[i]
+			found := false
+			for _, key := range instances {
+				if key.GetNamespace() == instance.GetNamespace() && key.GetName() == instance.GetName() {
+					found = true
+					break
+				}
+			}
+			if !found {
+				instances = append(instances, instance)
+			}
+		}
+		if len(instances) >= limit {
+			break
+		}
 	}
-	return len(list.Items) > 0, nil
+	return instances, nil
 }
 
-func (r *Reconciler[T]) isApiServiceUsed(ctx context.Context, apiService *apiregistrationv1.APIService, onlyForeign bool) (bool, error) {
+// return (up to limit) instances of (listable) types served by the given api service;
+// if onlyForeign is true, instances owned by the owner of the api service will be ignored;
+// if the api service is not available, and its types cannot be discovered or listed, no instances are reported
+func (r *Reconciler[T]) getApiServiceInstances(ctx context.Context, apiService *apiregistrationv1.APIService, onlyForeign bool, limit int) ([]types.ObjectKey, error) {
+	available := apiregistrationv1helper.IsAPIServiceConditionTrue(apiService, apiregistrationv1.Available)
 	gv := schema.GroupVersion{Group: apiService.Spec.Group, Version: apiService.Spec.Version}
 	resList, err := r.discoveryClient.ServerResourcesForGroupVersion(gv.String())
 	if err != nil {
-		return false, err
+		if !available {
+			return nil, nil
+		}
+		return nil, err
 	}
 	var kinds []string
-	for _, res := range resList.APIResources {
+	for i := range resList.APIResources {
+		res := &resList.APIResources[i]
+		// skip subresources, and types which cannot be listed
+		if strings.Contains(res.Name, "/") || !(discovery.SupportsAllVerbs{Verbs: []string{"list"}}).Match(gv.String(), res) {
+			continue
+		}
 		if !slices.Contains(kinds, res.Kind) {
 			kinds = append(kinds, res.Kind)
 		}
@@ -1055,6 +1713,7 @@ func (r *Reconciler[T]) isApiServiceUsed(ctx context.Context, apiService *apireg
 	if onlyForeign {
 		labelSelector = mustParseLabelSelector(r.labelKeyOwnerId + "!=" + apiService.Labels[r.labelKeyOwnerId])
 	}
+	var instances []types.ObjectKey
 	for _, kind := range kinds {
 		gvk := schema.GroupVersionKind{
 			Group:   apiService.Spec.Group,
@@ -1063,14 +1722,20 @@ func (r *Reconciler[T]) isApiServiceUsed(ctx context.Context, apiService *apireg
 		}
 		list := &unstructured.UnstructuredList{}
 		list.SetGroupVersionKind(gvk)
-		if err := r.client.List(ctx, list, &client.ListOptions{LabelSelector: labelSelector, Limit: 1}); err != nil {
-			return false, err
+		if err := r.client.List(ctx, list, &client.ListOptions{LabelSelector: labelSelector, Limit: int64(limit - len(instances))}); err != nil {
+			if !available {
+				return nil, nil
+			}
+			return nil, err
+		}
+		for i := range list.Items {
+			instances = append(instances, &list.Items[i])
 		}
-		if len(list.Items) > 0 {
-			return true, nil
+		if len(instances) >= limit {
+			break
 		}
 	}
-	return false, nil
+	return instances, nil
 }
 
 func (r *Reconciler[T]) isManaged(key types.ObjectKey, component Component) bool {
@@ -0,0 +1,95 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package component
+
+import (
+	"github.com/sap/go-generics/slices"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/component-operator-runtime/pkg/manifests"
+)
+
+// Create an ObjectTransformer which applies the given pod and container properties to all workloads
+// (see manifests.NewPodTemplateTransformer() for which objects are considered as workloads); both properties may be nil.
+// Properties which are set override the according settings of the generated pod templates, with the exception of
+// node selector, pod labels and pod annotations, which are merged into the existing values.
+// Container properties are applied to all (regular) containers, or, if containerNames are given, to the containers with these names.
+func NewKubernetesPropertiesTransformer(podProperties *KubernetesPodProperties, containerProperties *KubernetesContainerProperties, containerNames ...string) (manifests.ObjectTransformer, error) {
+	var podValues map[string]any
+	var containerValues map[string]any
+	if podProperties != nil {
+		values, err := runtime.DefaultUnstructuredConverter.ToUnstructured(podProperties)
+		if err != nil {
+			return nil, err
+		}
+		podValues = values
+	}
+	if containerProperties != nil {
+		values, err := runtime.DefaultUnstructuredConverter.ToUnstructured(containerProperties)
+		if err != nil {
+			return nil, err
+		}
+		containerValues = values
+	}
+
+	return manifests.NewPodTemplateTransformer(func(object client.Object, metadata map[string]any, spec map[string]any) error {
+		for key, value := range podValues {
+			switch key {
+			case "nodeSelector":
+				spec[key] = mergeUnstructured(spec[key], value)
+			case "podLabels":
+				metadata["labels"] = mergeUnstructured(metadata["labels"], value)
+			case "podAnnotations":
+				metadata["annotations"] = mergeUnstructured(metadata["annotations"], value)
+			case "podSecurityContext":
+				spec["securityContext"] = runtime.DeepCopyJSONValue(value)
+			default:
+				spec[key] = runtime.DeepCopyJSONValue(value)
+			}
+		}
+		if len(containerValues) > 0 {
+			containers, _ := spec["containers"].([]any)
+			for _, container := range containers {
+				container, ok := container.(map[string]any)
+				if !ok {
+					continue
+				}
+				if name, _ := container["name"].(string); len(containerNames) > 0 && !slices.Contains(containerNames, name) {
+					continue
+				}
+				for key, value := range containerValues {
+					container[key] = runtime.DeepCopyJSONValue(value)
+				}
+			}
+		}
+		return nil
+	}), nil
+}
+
+// merge the (unstructured) map y into x, and return the result; if x is not a map, a copy of y is returned
+func mergeUnstructured(x any, y any) any {
+	m, ok := x.(map[string]any)
+	if !ok {
+		return runtime.DeepCopyJSONValue(y)
+	}
+	for k, v := range y.(map[string]any) {
+		m[k] = runtime.DeepCopyJSONValue(v)
+	}
+	return m
+}
@@ -65,6 +65,8 @@ type Status struct {
 	// +kubebuilder:validation:Enum=Processing;Deleting;Ready;Error
 	State     State            `json:"state,omitempty"`
 	Inventory []*InventoryItem `json:"inventory,omitempty"`
+	// Usage notes, as rendered by the generator (if it implements manifests.NotesGenerator).
+	Notes string `json:"notes,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
@@ -19,6 +19,9 @@ package component
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/sap/go-generics/slices"
 
@@ -29,6 +32,7 @@ import (
 	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/sap/component-operator-runtime/pkg/manifests"
 	"github.com/sap/component-operator-runtime/pkg/types"
 )
 
@@ -55,6 +59,21 @@ func setAnnotation(obj client.Object, key string, value string) {
 	obj.SetAnnotations(annotations)
 }
 
+func getAnnotationInt(obj client.Object, key string, minValue int, maxValue int, defaultValue int) (int, error) {
+	if value, ok := obj.GetAnnotations()[key]; ok {
+		value, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, err
+		}
+		if value < minValue || value > maxValue {
+			return 0, fmt.Errorf("value %d not in allowed range [%d,%d]", value, minValue, maxValue)
+		}
+		return value, nil
+	} else {
+		return defaultValue, nil
+	}
+}
+
 func isNamespace(key types.ObjectKey) bool {
 	return key.GetObjectKind().GroupVersionKind().GroupKind() == schema.GroupKind{Group: "", Kind: "Namespace"}
 }
@@ -67,6 +86,61 @@ func isApiService(key types.ObjectKey) bool {
 	return key.GetObjectKind().GroupVersionKind().GroupKind() == schema.GroupKind{Group: "apiregistration.k8s.io", Kind: "APIService"}
 }
 
+// get release information for given component;
+// a release is an upgrade if the component was applied successfully before, and still has dependent objects
+func getReleaseInfo(component Component) manifests.ReleaseInfo {
+	status := component.GetStatus()
+	isUpgrade := status.AppliedGeneration > 0 && len(status.Inventory) > 0
+	return manifests.ReleaseInfo{
+		IsInstall: !isUpgrade,
+		IsUpgrade: isUpgrade,
+		Revision:  component.GetGeneration(),
+	}
+}
+
+// check whether the custom resource definitions or api services contained in the given inventories differ by presence or digest,
+// or whether one of them became ready; other phase transitions are not considered
+func apiExtensionsChanged(inventory1 []*InventoryItem, inventory2 []*InventoryItem) bool {
+	collect := func(inventory []*InventoryItem) map[string]*InventoryItem {
+		result := make(map[string]*InventoryItem)
+		for _, item := range inventory {
+			if isCrd(item) || isApiService(item) {
+				result[item.String()] = item
+			}
+		}
+		return result
+	}
+	items1 := collect(inventory1)
+	items2 := collect(inventory2)
+	if len(items1) != len(items2) {
+		return true
+	}
+	for key, item2 := range items2 {
+		item1, ok := items1[key]
+		if !ok || item1.Digest != item2.Digest || item1.Phase != PhaseReady && item2.Phase == PhaseReady {
+			return true
+		}
+	}
+	return false
+}
+
+func isEvent(groupKind schema.GroupKind) bool {
+	return groupKind == schema.GroupKind{Group: "", Kind: "Event"} || groupKind == schema.GroupKind{Group: "events.k8s.io", Kind: "Event"}
+}
+
+// check whether the given object is created by Kubernetes in every namespace
+// (and therefore can be ignored when checking whether a namespace is empty)
+func isNamespaceBoilerplate(groupKind schema.GroupKind, name string) bool {
+	switch groupKind {
+	case schema.GroupKind{Group: "", Kind: "ServiceAccount"}:
+		return name == "default"
+	case schema.GroupKind{Group: "", Kind: "ConfigMap"}:
+		return name == "kube-root-ca.crt"
+	default:
+		return false
+	}
+}
+
 func getCrds(objects []client.Object) []*apiextensionsv1.CustomResourceDefinition {
 	var crds []*apiextensionsv1.CustomResourceDefinition
 	for _, object := range objects {
@@ -160,49 +234,108 @@ func scopeFromCrd(crd *apiextensionsv1.CustomResourceDefinition) int {
 	}
 }
 
-func sortObjectsForApply[T client.Object](s []T, orderFunc func(client.Object) int) []T {
-	priority := map[string]int{
-		"Namespace": -4,
-		"ValidatingWebhookConfiguration.admissionregistration.k8s.io": -3,
-		"MutatingWebhookConfiguration.admissionregistration.k8s.io":   -3,
-		"CustomResourceDefinition.apiextensions.k8s.io":               -2,
-		"ConfigMap":                             -1,
-		"Secret":                                -1,
-		"ClusterRole.rbac.authorization.k8s.io": -1,
-		"Role.rbac.authorization.k8s.io":        -1,
-		"ClusterRoleBinding.rbac.authorization.k8s.io": -1,
-		"RoleBinding.rbac.authorization.k8s.io":        -1,
-		"APIService.apiregistration.k8s.io":            1,
+// Default priorities used when sorting dependent objects for application (lower priorities come first);
+// kinds not listed here have priority 0.
+func defaultApplyPriorities() map[schema.GroupKind]int {
+	return map[schema.GroupKind]int{
+		{Group: "", Kind: "Namespace"}: -4,
+		{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: -3,
+		{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   -3,
+		{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:               -2,
+		{Group: "", Kind: "ConfigMap"}:                                                  -1,
+		{Group: "", Kind: "Secret"}:                                                     -1,
+		{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}:                       -1,
+		{Group: "rbac.authorization.k8s.io", Kind: "Role"}:                              -1,
+		{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:                -1,
+		{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding"}:                       -1,
+		{Group: "apiregistration.k8s.io", Kind: "APIService"}:                           1,
 	}
+}
+
+// Default priorities used when sorting dependent objects for deletion (lower priorities come first);
+// kinds not listed here have priority 0.
+func defaultDeletePriorities() map[schema.GroupKind]int {
+	return map[schema.GroupKind]int{
+		{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:               -1,
+		{Group: "apiregistration.k8s.io", Kind: "APIService"}:                           -1,
+		{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: 1,
+		{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   1,
+		{Group: "", Kind: "Service"}:                                                    2,
+		{Group: "", Kind: "ConfigMap"}:                                                  2,
+		{Group: "", Kind: "Secret"}:                                                     2,
+		{Group: "", Kind: "Namespace"}:                                                  3,
+	}
+}
+
+func sortObjectsForApply[T client.Object](s []T, orderFunc func(client.Object) int, priority map[schema.GroupKind]int) []T {
 	f := func(x T, y T) bool {
 		orderx := orderFunc(x)
 		ordery := orderFunc(y)
-		gvx := x.GetObjectKind().GroupVersionKind().GroupKind().String()
-		gvy := y.GetObjectKind().GroupVersionKind().GroupKind().String()
-		return orderx > ordery || orderx == ordery && priority[gvx] > priority[gvy]
+		gkx := x.GetObjectKind().GroupVersionKind().GroupKind()
+		gky := y.GetObjectKind().GroupVersionKind().GroupKind()
+		return orderx > ordery || orderx == ordery && priority[gkx] > priority[gky]
 	}
 	return slices.SortBy(s, f)
 }
 
-func sortObjectsForDelete[T types.ObjectKey](s []T) []T {
-	priority := map[string]int{
-		"CustomResourceDefinition.apiextensions.k8s.io":               -1,
-		"APIService.apiregistration.k8s.io":                           -1,
-		"ValidatingWebhookConfiguration.admissionregistration.k8s.io": 1,
-		"MutatingWebhookConfiguration.admissionregistration.k8s.io":   1,
-		"Service":   2,
-		"ConfigMap": 2,
-		"Secret":    2,
-		"Namespace": 3,
-	}
+func sortObjectsForDelete[T types.ObjectKey](s []T, priority map[schema.GroupKind]int) []T {
 	f := func(x T, y T) bool {
-		gvx := x.GetObjectKind().GroupVersionKind().GroupKind().String()
-		gvy := y.GetObjectKind().GroupVersionKind().GroupKind().String()
-		return priority[gvx] > priority[gvy]
+		gkx := x.GetObjectKind().GroupVersionKind().GroupKind()
+		gky := y.GetObjectKind().GroupVersionKind().GroupKind()
+		return priority[gkx] > priority[gky]
 	}
 	return slices.SortBy(s, f)
 }
 
+// return a human-readable description of the given objects, grouped by type; at most max objects will be listed
+func describeObjects(keys []types.ObjectKey, max int) string {
+	var gvks []schema.GroupVersionKind
+	names := make(map[schema.GroupVersionKind][]string)
+	for i, key := range keys {
+		if i >= max {
+			break
+		}
+		gvk := key.GetObjectKind().GroupVersionKind()
+		if _, ok := names[gvk]; !ok {
+			gvks = append(gvks, gvk)
+		}
+		name := key.GetName()
+		if namespace := key.GetNamespace(); namespace != "" {
+			name = namespace + "/" + name
+		}
+		names[gvk] = append(names[gvk], name)
+	}
+	var descriptions []string
+	for _, gvk := range gvks {
+		apiVersion, kind := gvk.ToAPIVersionAndKind()
+		descriptions = append(descriptions, fmt.Sprintf("%s %s %s", apiVersion, kind, strings.Join(names[gvk], ", ")))
+	}
+	description := strings.Join(descriptions, "; ")
+	if len(keys) > max {
+		description += ", ..."
+	}
+	return description
+}
+
+func containsObject[T types.ObjectKey](objects []T, key types.ObjectKey) bool {
+	for _, object := range objects {
+		if object.GetObjectKind().GroupVersionKind().GroupKind() == key.GetObjectKind().GroupVersionKind().GroupKind() && object.GetNamespace() == key.GetNamespace() && object.GetName() == key.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
+// check if some of the given inventory items is being created or purged (for example, a delete hook which is still running)
+func hasItemsInProgress(inventory []*InventoryItem) bool {
+	for _, item := range inventory {
+		if item.Phase == PhaseScheduledForApplication || item.Phase == PhaseCreating || item.Phase == PhaseCompleting {
+			return true
+		}
+	}
+	return false
+}
+
 func getItem(inventory []*InventoryItem, key types.ObjectKey) *InventoryItem {
 	var item *InventoryItem
 	for _, _item := range inventory {
@@ -0,0 +1,208 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/component-operator-runtime/internal/cache"
+	"github.com/sap/component-operator-runtime/internal/capabilities"
+	"github.com/sap/component-operator-runtime/pkg/types"
+)
+
+// Default maximum number of entries held by a CachingGenerator.
+const DefaultCachingGeneratorMaxEntries = 100
+
+// CachingGenerator is a Generator implementation which wraps another generator, and caches its results.
+// Results are cached by a digest of namespace, name, parameters, release information, the identity of the component (if called through GenerateWithContext()),
+// and the given generator version; cached results are discarded whenever cached cluster capabilities change (see the Helm generator),
+// and expire after capabilities.DefaultTTL (such that changed capabilities are eventually discovered, even if all results are taken from the cache).
+// A result is not cached if the wrapped generator called MarkUncacheable() on the passed context (which is the case if the lookup
+// template function was used), or if it called generators or transformers which are not context-aware (and therefore cannot report uncacheable results);
+// the latter is not the case if CachingGeneratorOptions.AssumeDeterministic is set.
+// Results are deep-copied, such that callers may modify the returned objects. Notes are not cached.
+type CachingGenerator struct {
+	generator            Generator
+	version              string
+	keyComponentMetadata bool
+	assumeDeterministic  bool
+	cache                *cache.LRU[string, *cachedResult]
+}
+
+type cachedResult struct {
+	objects   []client.Object
+	expiresAt time.Time
+}
+
+// CachingGeneratorOptions allows to tweak the behavior of the caching generator.
+type CachingGeneratorOptions struct {
+	// Maximum number of cached results; if zero, DefaultCachingGeneratorMaxEntries is used.
+	MaxEntries int
+	// Whether labels and annotations of the component are included into the cache key;
+	// must be set if the wrapped generator (or one of its transformers) evaluates them.
+	KeyComponentMetadata bool
+	// Whether results are cached although the wrapped generator is (or calls generators or transformers which are) not context-aware;
+	// must only be set if these are deterministic (in particular, do not depend on the cluster state).
+	AssumeDeterministic bool
+}
+
+var _ ReleaseAwareGenerator = &CachingGenerator{}
+var _ NotesGenerator = &CachingGenerator{}
+var _ GeneratorWithContext = &CachingGenerator{}
+var _ NotesGeneratorWithContext = &CachingGenerator{}
+
+// Create a new CachingGenerator, wrapping the given generator; version should identify the wrapped generator (for example the version of
+// the used Helm chart), and maxEntries limits the number of cached results (if zero, DefaultCachingGeneratorMaxEntries is used);
+// if full, the least recently used result is evicted.
+func NewCachingGenerator(generator Generator, version string, maxEntries int) *CachingGenerator {
+	return NewCachingGeneratorWithOptions(generator, version, CachingGeneratorOptions{MaxEntries: maxEntries})
+}
+
+// Create a new CachingGenerator with options.
+func NewCachingGeneratorWithOptions(generator Generator, version string, options CachingGeneratorOptions) *CachingGenerator {
+	maxEntries := options.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultCachingGeneratorMaxEntries
+	}
+	return &CachingGenerator{
+		generator:            generator,
+		version:              version,
+		keyComponentMetadata: options.KeyComponentMetadata,
+		assumeDeterministic:  options.AssumeDeterministic,
+		cache:                cache.NewLRU[string, *cachedResult](maxEntries),
+	}
+}
+
+// Generate resource descriptors.
+func (g *CachingGenerator) Generate(namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	return g.generate(context.Background(), ReconcileInfo{}, namespace, name, parameters)
+}
+
+// Generate resource descriptors for the given release.
+func (g *CachingGenerator) GenerateRelease(namespace string, name string, parameters types.Unstructurable, release ReleaseInfo) ([]client.Object, error) {
+	return g.generate(context.Background(), ReconcileInfo{Release: &release}, namespace, name, parameters)
+}
+
+// Generate resource descriptors, passing the context and reconcile information to the wrapped generator.
+func (g *CachingGenerator) GenerateWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	return g.generate(ctx, info, namespace, name, parameters)
+}
+
+// Generate notes, by calling the wrapped generator (if it implements NotesGenerator); notes are not cached.
+func (g *CachingGenerator) GenerateNotes(namespace string, name string, parameters types.Unstructurable, release ReleaseInfo) (string, error) {
+	if generator, ok := g.generator.(NotesGenerator); ok {
+		return generator.GenerateNotes(namespace, name, parameters, release)
+	}
+	return "", nil
+}
+
+// Generate notes, passing the context and reconcile information to the wrapped generator (if it implements NotesGenerator); notes are not cached.
+func (g *CachingGenerator) GenerateNotesWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) (string, error) {
+	if generator, ok := g.generator.(NotesGenerator); ok {
+		return generateNotes(ctx, generator, info, namespace, name, parameters)
+	}
+	return "", nil
+}
+
+// Remove all cached results.
+func (g *CachingGenerator) Purge() {
+	g.cache.Purge()
+}
+
+func (g *CachingGenerator) generate(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	capabilitiesGeneration := capabilities.Generation()
+	key, err := g.key(capabilitiesGeneration, info, namespace, name, parameters)
+	if err != nil {
+		return nil, err
+	}
+	// note: expired results are regenerated, which lets the wrapped generator re-discover expired capabilities
+	if result, ok := g.cache.Get(key); ok && time.Now().Before(result.expiresAt) {
+		return deepCopyObjects(result.objects), nil
+	}
+
+	ctx, isUncacheable := cache.WithCacheability(ctx, g.assumeDeterministic)
+	var objects []client.Object
+	if generator, ok := g.generator.(GeneratorWithContext); ok {
+		objects, err = generator.GenerateWithContext(ctx, info, namespace, name, parameters)
+	} else if generator, ok := g.generator.(ReleaseAwareGenerator); ok && info.Release != nil {
+		cache.MarkOpaque(ctx)
+		objects, err = generator.GenerateRelease(namespace, name, parameters, *info.Release)
+	} else {
+		cache.MarkOpaque(ctx)
+		objects, err = g.generator.Generate(namespace, name, parameters)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !isUncacheable() {
+		// note: capabilities may have been re-discovered while generating, so the result is keyed by the current capabilities generation
+		if generation := capabilities.Generation(); generation != capabilitiesGeneration {
+			if key, err = g.key(generation, info, namespace, name, parameters); err != nil {
+				return nil, err
+			}
+		}
+		g.cache.Add(key, &cachedResult{objects: deepCopyObjects(objects), expiresAt: time.Now().Add(capabilities.DefaultTTL)})
+	}
+	return objects, nil
+}
+
+func (g *CachingGenerator) key(capabilitiesGeneration uint64, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) (string, error) {
+	input := map[string]any{
+		"version":                g.version,
+		"capabilitiesGeneration": capabilitiesGeneration,
+		"namespace":              namespace,
+		"name":                   name,
+		"parameters":             parameters.ToUnstructured(),
+	}
+	if info.Release != nil {
+		input["release"] = *info.Release
+	}
+	if component := info.Component; component != nil {
+		componentInput := map[string]any{
+			"namespace":  component.GetNamespace(),
+			"name":       component.GetName(),
+			"uid":        component.GetUID(),
+			"generation": component.GetGeneration(),
+		}
+		if g.keyComponentMetadata {
+			componentInput["labels"] = component.GetLabels()
+			componentInput["annotations"] = component.GetAnnotations()
+		}
+		input["component"] = componentInput
+	}
+	// note: map keys are serialized in sorted order, so the digest is deterministic
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func deepCopyObjects(objects []client.Object) []client.Object {
+	result := make([]client.Object, len(objects))
+	for i, object := range objects {
+		result[i] = object.DeepCopyObject().(client.Object)
+	}
+	return result
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifests
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/component-operator-runtime/internal/cache"
+	"github.com/sap/component-operator-runtime/pkg/types"
+)
+
+// CompositeGenerator is a Generator implementation that combines the results of multiple generators.
+// The children are called in the given order, and the generated objects are concatenated;
+// it is an error if two children generate objects with the same group, kind, namespace and name.
+type CompositeGenerator struct {
+	name     string
+	children []CompositeGeneratorChild
+}
+
+// A child of a CompositeGenerator.
+type CompositeGeneratorChild struct {
+	// The generator.
+	Generator Generator
+	// Optional function selecting the parameters passed to this child; if not set, the child receives
+	// the parameters passed to the CompositeGenerator.
+	ParameterSelector func(parameters types.Unstructurable) (types.Unstructurable, error)
+	// Offset added to the order (that is, the annotation <name>/order) of all objects generated by this child;
+	// objects without explicit order are treated as order 0.
+	OrderOffset int
+}
+
+var _ ReleaseAwareGenerator = &CompositeGenerator{}
+var _ NotesGenerator = &CompositeGenerator{}
+var _ GeneratorWithContext = &CompositeGenerator{}
+var _ NotesGeneratorWithContext = &CompositeGenerator{}
+
+// Create a new CompositeGenerator.
+// Here, name must be identical to the name used for the related Reconciler (it is needed to manage the order annotation).
+func NewCompositeGenerator(name string, children ...CompositeGeneratorChild) *CompositeGenerator {
+	return &CompositeGenerator{
+		name:     name,
+		children: children,
+	}
+}
+
+// Create a new CompositeGenerator with a ParameterTransformer attached (further transformers can be attached to the reeturned generator object).
+func NewCompositeGeneratorWithParameterTransformer(name string, transformer ParameterTransformer, children ...CompositeGeneratorChild) TransformableGenerator {
+	return NewGenerator(NewCompositeGenerator(name, children...)).WithParameterTransformer(transformer)
+}
+
+// Create a new CompositeGenerator with an ObjectTransformer attached (further transformers can be attached to the reeturned generator object).
+func NewCompositeGeneratorWithObjectTransformer(name string, transformer ObjectTransformer, children ...CompositeGeneratorChild) TransformableGenerator {
+	return NewGenerator(NewCompositeGenerator(name, children...)).WithObjectTransformer(transformer)
+}
+
+// Return a parameter selector (to be used as CompositeGeneratorChild.ParameterSelector), which selects the value at the given
+// dot-separated path of the (unstructured) parameters; the selected value must be a map; if the path does not exist, empty parameters are returned.
+func SelectParameterPath(path string) func(parameters types.Unstructurable) (types.Unstructurable, error) {
+	return func(parameters types.Unstructurable) (types.Unstructurable, error) {
+		var value any = parameters.ToUnstructured()
+		for _, key := range strings.Split(path, ".") {
+			if value == nil {
+				break
+			}
+			m, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("error selecting parameter path %s: %s is not a map", path, key)
+			}
+			value = m[key]
+		}
+		if value == nil {
+			return types.UnstructurableMap(map[string]any{}), nil
+		}
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("error selecting parameter path %s: value is not a map", path)
+		}
+		return types.UnstructurableMap(m), nil
+	}
+}
+
+// Generate resource descriptors.
+func (g *CompositeGenerator) Generate(namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	return g.generate(context.Background(), ReconcileInfo{}, false, namespace, name, parameters)
+}
+
+// Generate resource descriptors, passing release information to children implementing ReleaseAwareGenerator.
+func (g *CompositeGenerator) GenerateRelease(namespace string, name string, parameters types.Unstructurable, release ReleaseInfo) ([]client.Object, error) {
+	return g.generate(context.Background(), ReconcileInfo{Release: &release}, false, namespace, name, parameters)
+}
+
+// Generate resource descriptors, passing the context and reconcile information to children implementing GeneratorWithContext
+// (and the release information to children implementing ReleaseAwareGenerator).
+func (g *CompositeGenerator) GenerateWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	return g.generate(ctx, info, true, namespace, name, parameters)
+}
+
+// Generate notes, by concatenating the notes of all children implementing NotesGenerator.
+func (g *CompositeGenerator) GenerateNotes(namespace string, name string, parameters types.Unstructurable, release ReleaseInfo) (string, error) {
+	return g.generateNotes(context.Background(), ReconcileInfo{Release: &release}, false, namespace, name, parameters)
+}
+
+// Generate notes, passing the context and reconcile information to children implementing NotesGeneratorWithContext.
+func (g *CompositeGenerator) GenerateNotesWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) (string, error) {
+	return g.generateNotes(ctx, info, true, namespace, name, parameters)
+}
+
+func (g *CompositeGenerator) generateNotes(ctx context.Context, info ReconcileInfo, withContext bool, namespace string, name string, parameters types.Unstructurable) (string, error) {
+	var notes []string
+	for i, child := range g.children {
+		generator, ok := child.Generator.(NotesGenerator)
+		if !ok {
+			continue
+		}
+		childParameters, err := g.selectParameters(i, parameters)
+		if err != nil {
+			return "", err
+		}
+		var childNotes string
+		if withContext {
+			childNotes, err = generateNotes(ctx, generator, info, namespace, name, childParameters)
+		} else {
+			childNotes, err = generator.GenerateNotes(namespace, name, childParameters, *info.Release)
+		}
+		if err != nil {
+			return "", errors.Wrapf(err, "error generating notes for child generator (%d)", i)
+		}
+		if childNotes = strings.TrimSpace(childNotes); childNotes != "" {
+			notes = append(notes, childNotes)
+		}
+	}
+	return strings.Join(notes, "\n\n"), nil
+}
+
+func (g *CompositeGenerator) generate(ctx context.Context, info ReconcileInfo, withContext bool, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	annotationKeyOrder := g.name + "/order"
+
+	var allObjects []client.Object
+	owners := make(map[string]int)
+	for i, child := range g.children {
+		childParameters, err := g.selectParameters(i, parameters)
+		if err != nil {
+			return nil, err
+		}
+		var objects []client.Object
+		if generator, ok := child.Generator.(GeneratorWithContext); ok && withContext {
+			objects, err = generator.GenerateWithContext(ctx, info, namespace, name, childParameters)
+		} else if generator, ok := child.Generator.(ReleaseAwareGenerator); ok && info.Release != nil {
+			cache.MarkOpaque(ctx)
+			objects, err = generator.GenerateRelease(namespace, name, childParameters, *info.Release)
+		} else {
+			cache.MarkOpaque(ctx)
+			objects, err = child.Generator.Generate(namespace, name, childParameters)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "error calling child generator (%d)", i)
+		}
+		for _, object := range objects {
+			gvk := object.GetObjectKind().GroupVersionKind()
+			// note: the version is ignored, since different versions of the same kind address the same object
+			key := fmt.Sprintf("%s/%s/%s/%s", gvk.Group, gvk.Kind, object.GetNamespace(), object.GetName())
+			if owner, ok := owners[key]; ok {
+				return nil, fmt.Errorf("duplicate object %s (generated by child generators %d and %d)", types.ObjectKeyToString(object), owner, i)
+			}
+			owners[key] = i
+			if child.OrderOffset != 0 {
+				annotations := object.GetAnnotations()
+				if annotations == nil {
+					annotations = make(map[string]string)
+				}
+				order := 0
+				if value, ok := annotations[annotationKeyOrder]; ok {
+					order, err = strconv.Atoi(value)
+					if err != nil {
+						return nil, errors.Wrapf(err, "invalid value for annotation %s (object: %s)", annotationKeyOrder, types.ObjectKeyToString(object))
+					}
+				}
+				annotations[annotationKeyOrder] = strconv.Itoa(order + child.OrderOffset)
+				object.SetAnnotations(annotations)
+			}
+		}
+		allObjects = append(allObjects, objects...)
+	}
+	return allObjects, nil
+}
+
+func (g *CompositeGenerator) selectParameters(i int, parameters types.Unstructurable) (types.Unstructurable, error) {
+	if g.children[i].ParameterSelector == nil {
+		return parameters, nil
+	}
+	parameters, err := g.children[i].ParameterSelector(parameters)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error selecting parameters for child generator (%d)", i)
+	}
+	return parameters, nil
+}
@@ -0,0 +1,471 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/component-operator-runtime/internal/cache"
+	"github.com/sap/component-operator-runtime/pkg/types"
+)
+
+// Predicate over generator parameters.
+type Predicate func(parameters types.Unstructurable) (bool, error)
+
+// ConditionalGenerator is a Generator implementation that delegates to one of two generators, depending on a predicate
+// evaluated against the parameters. If the predicate is false, and no else generator was specified, no objects are generated.
+type ConditionalGenerator struct {
+	predicate     Predicate
+	generator     Generator
+	elseGenerator Generator
+}
+
+var _ ReleaseAwareGenerator = &ConditionalGenerator{}
+var _ NotesGenerator = &ConditionalGenerator{}
+var _ GeneratorWithContext = &ConditionalGenerator{}
+var _ NotesGeneratorWithContext = &ConditionalGenerator{}
+
+// Create a new ConditionalGenerator; elseGenerator is optional (may be nil).
+func NewConditionalGenerator(predicate Predicate, generator Generator, elseGenerator Generator) *ConditionalGenerator {
+	return &ConditionalGenerator{
+		predicate:     predicate,
+		generator:     generator,
+		elseGenerator: elseGenerator,
+	}
+}
+
+// Create a new ConditionalGenerator, using a predicate expression (see ParsePredicate() for the syntax); elseGenerator is optional (may be nil).
+func NewConditionalGeneratorFromExpression(expression string, generator Generator, elseGenerator Generator) (*ConditionalGenerator, error) {
+	predicate, err := ParsePredicate(expression)
+	if err != nil {
+		return nil, err
+	}
+	return NewConditionalGenerator(predicate, generator, elseGenerator), nil
+}
+
+// Generate resource descriptors.
+func (g *ConditionalGenerator) Generate(namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	generator, err := g.selectGenerator(parameters)
+	if err != nil || generator == nil {
+		return nil, err
+	}
+	return generator.Generate(namespace, name, parameters)
+}
+
+// Generate resource descriptors, passing release information to the selected generator (if it implements ReleaseAwareGenerator).
+func (g *ConditionalGenerator) GenerateRelease(namespace string, name string, parameters types.Unstructurable, release ReleaseInfo) ([]client.Object, error) {
+	generator, err := g.selectGenerator(parameters)
+	if err != nil || generator == nil {
+		return nil, err
+	}
+	if generator, ok := generator.(ReleaseAwareGenerator); ok {
+		return generator.GenerateRelease(namespace, name, parameters, release)
+	}
+	return generator.Generate(namespace, name, parameters)
+}
+
+// Generate resource descriptors, passing the context and reconcile information to the selected generator (if it implements GeneratorWithContext,
+// otherwise the release information is passed, if the selected generator implements ReleaseAwareGenerator).
+func (g *ConditionalGenerator) GenerateWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	generator, err := g.selectGenerator(parameters)
+	if err != nil || generator == nil {
+		return nil, err
+	}
+	if generator, ok := generator.(GeneratorWithContext); ok {
+		return generator.GenerateWithContext(ctx, info, namespace, name, parameters)
+	}
+	cache.MarkOpaque(ctx)
+	if generator, ok := generator.(ReleaseAwareGenerator); ok && info.Release != nil {
+		return generator.GenerateRelease(namespace, name, parameters, *info.Release)
+	}
+	return generator.Generate(namespace, name, parameters)
+}
+
+// Generate notes, if the selected generator implements NotesGenerator.
+func (g *ConditionalGenerator) GenerateNotes(namespace string, name string, parameters types.Unstructurable, release ReleaseInfo) (string, error) {
+	generator, err := g.selectGenerator(parameters)
+	if err != nil || generator == nil {
+		return "", err
+	}
+	if generator, ok := generator.(NotesGenerator); ok {
+		return generator.GenerateNotes(namespace, name, parameters, release)
+	}
+	return "", nil
+}
+
+// Generate notes, passing the context and reconcile information to the selected generator (if it implements NotesGeneratorWithContext).
+func (g *ConditionalGenerator) GenerateNotesWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) (string, error) {
+	generator, err := g.selectGenerator(parameters)
+	if err != nil || generator == nil {
+		return "", err
+	}
+	if generator, ok := generator.(NotesGenerator); ok {
+		return generateNotes(ctx, generator, info, namespace, name, parameters)
+	}
+	return "", nil
+}
+
+func (g *ConditionalGenerator) selectGenerator(parameters types.Unstructurable) (Generator, error) {
+	ok, err := g.predicate(parameters)
+	if err != nil {
+		return nil, errors.Wrap(err, "error evaluating predicate")
+	}
+	if ok {
+		return g.generator, nil
+	}
+	return g.elseGenerator, nil
+}
+
+// Parse a predicate expression. Expressions are evaluated against the (unstructured) parameters, and have the following syntax:
+//   - a path (such as .monitoring.enabled, or .ingresses[0].host) selects a single value from the parameters, using JSONPath semantics;
+//     used standalone, the path is true if the selected value exists and is not false, zero, empty or null
+//   - a path can be compared with a JSON literal (such as "abc", 1, true or null) by ==, !=, <, <=, >, >=; ordering comparisons require numbers or strings
+//   - expressions can be combined by ! (not), && (and), || (or), and grouped by parentheses.
+//
+// For example: .monitoring.enabled && .monitoring.mode != "external".
+func ParsePredicate(expression string) (Predicate, error) {
+	tokens, err := tokenizePredicate(expression)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing predicate expression %q", expression)
+	}
+	p := predicateParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected token %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing predicate expression %q", expression)
+	}
+	return func(parameters types.Unstructurable) (bool, error) {
+		return node(parameters.ToUnstructured())
+	}, nil
+}
+
+// Parse a predicate expression, panicking in case of errors (see ParsePredicate() for the syntax).
+func MustParsePredicate(expression string) Predicate {
+	predicate, err := ParsePredicate(expression)
+	if err != nil {
+		panic(err)
+	}
+	return predicate
+}
+
+type predicateTokenKind int
+
+const (
+	predicateTokenOperator predicateTokenKind = iota
+	predicateTokenPath
+	predicateTokenLiteral
+)
+
+type predicateToken struct {
+	kind predicateTokenKind
+	text string
+}
+
+func tokenizePredicate(expression string) ([]predicateToken, error) {
+	var tokens []predicateToken
+	s := expression
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s == "" {
+			return tokens, nil
+		}
+		switch {
+		case strings.HasPrefix(s, "&&"), strings.HasPrefix(s, "||"), strings.HasPrefix(s, "=="), strings.HasPrefix(s, "!="), strings.HasPrefix(s, "<="), strings.HasPrefix(s, ">="):
+			tokens = append(tokens, predicateToken{kind: predicateTokenOperator, text: s[:2]})
+			s = s[2:]
+		case s[0] == '!' || s[0] == '<' || s[0] == '>' || s[0] == '(' || s[0] == ')':
+			tokens = append(tokens, predicateToken{kind: predicateTokenOperator, text: s[:1]})
+			s = s[1:]
+		case s[0] == '.':
+			n := 1
+			depth := 0
+			for ; n < len(s); n++ {
+				if s[n] == '[' {
+					depth++
+				} else if s[n] == ']' {
+					depth--
+				} else if depth == 0 && !(unicode.IsLetter(rune(s[n])) || unicode.IsDigit(rune(s[n])) || strings.ContainsRune("._-", rune(s[n]))) {
+					break
+				}
+			}
+			tokens = append(tokens, predicateToken{kind: predicateTokenPath, text: s[:n]})
+			s = s[n:]
+		case s[0] == '"':
+			n := 1
+			for ; n < len(s) && s[n] != '"'; n++ {
+				if s[n] == '\\' {
+					n++
+				}
+			}
+			if n >= len(s) {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			tokens = append(tokens, predicateToken{kind: predicateTokenLiteral, text: s[:n+1]})
+			s = s[n+1:]
+		default:
+			n := strings.IndexFunc(s, func(r rune) bool { return unicode.IsSpace(r) || strings.ContainsRune("()!&|=<>", r) })
+			if n < 0 {
+				n = len(s)
+			}
+			if n == 0 {
+				return nil, fmt.Errorf("unexpected character %q", s[0])
+			}
+			tokens = append(tokens, predicateToken{kind: predicateTokenLiteral, text: s[:n]})
+			s = s[n:]
+		}
+	}
+}
+
+type predicateNode func(values map[string]any) (bool, error)
+
+type predicateParser struct {
+	tokens []predicateToken
+	pos    int
+}
+
+func (p *predicateParser) peekOperator(text string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == predicateTokenOperator && p.tokens[p.pos].text == text
+}
+
+func (p *predicateParser) parseOr() (predicateNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOperator("||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(values map[string]any) (bool, error) {
+			if ok, err := l(values); err != nil || ok {
+				return ok, err
+			}
+			return right(values)
+		}
+	}
+	return left, nil
+}
+
+func (p *predicateParser) parseAnd() (predicateNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOperator("&&") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(values map[string]any) (bool, error) {
+			if ok, err := l(values); err != nil || !ok {
+				return ok, err
+			}
+			return right(values)
+		}
+	}
+	return left, nil
+}
+
+func (p *predicateParser) parseUnary() (predicateNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	if p.peekOperator("!") {
+		p.pos++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(values map[string]any) (bool, error) {
+			ok, err := node(values)
+			return !ok, err
+		}, nil
+	}
+	if p.peekOperator("(") {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peekOperator(")") {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *predicateParser) parseComparison() (predicateNode, error) {
+	token := p.tokens[p.pos]
+	if token.kind != predicateTokenPath {
+		return nil, fmt.Errorf("unexpected token %q (expected path)", token.text)
+	}
+	p.pos++
+	path := jsonpath.New(token.text).AllowMissingKeys(true)
+	if err := path.Parse("{" + token.text + "}"); err != nil {
+		return nil, errors.Wrapf(err, "invalid path %s", token.text)
+	}
+	lookup := func(values map[string]any) (any, error) {
+		results, err := path.FindResults(values)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error evaluating path %s", token.text)
+		}
+		if len(results) == 0 || len(results[0]) == 0 {
+			return nil, nil
+		}
+		if len(results) > 1 || len(results[0]) > 1 {
+			return nil, fmt.Errorf("path %s selects more than one value", token.text)
+		}
+		return results[0][0].Interface(), nil
+	}
+
+	operator := ""
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == predicateTokenOperator {
+		switch p.tokens[p.pos].text {
+		case "==", "!=", "<", "<=", ">", ">=":
+			operator = p.tokens[p.pos].text
+		}
+	}
+	if operator == "" {
+		return func(values map[string]any) (bool, error) {
+			value, err := lookup(values)
+			if err != nil {
+				return false, err
+			}
+			return isTruthy(value), nil
+		}, nil
+	}
+	p.pos++
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != predicateTokenLiteral {
+		return nil, fmt.Errorf("expected literal after operator %s", operator)
+	}
+	var literal any
+	if err := json.Unmarshal([]byte(p.tokens[p.pos].text), &literal); err != nil {
+		return nil, fmt.Errorf("invalid literal %s", p.tokens[p.pos].text)
+	}
+	p.pos++
+	return func(values map[string]any) (bool, error) {
+		value, err := lookup(values)
+		if err != nil {
+			return false, err
+		}
+		return compareValues(value, operator, literal)
+	}, nil
+}
+
+// check whether value is set, and not false, zero or empty
+func isTruthy(value any) bool {
+	if value == nil {
+		return false
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() != 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() != 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() != 0
+	case reflect.String, reflect.Map, reflect.Slice:
+		return v.Len() > 0
+	default:
+		return true
+	}
+}
+
+func compareValues(value any, operator string, literal any) (bool, error) {
+	if x, ok := toFloat(value); ok {
+		value = x
+	}
+	if operator == "==" || operator == "!=" {
+		equal := equality.Semantic.DeepEqual(value, literal)
+		return equal == (operator == "=="), nil
+	}
+	var cmp int
+	if x, ok := value.(float64); ok {
+		y, ok := literal.(float64)
+		if !ok {
+			return false, fmt.Errorf("cannot compare number with %v", literal)
+		}
+		cmp = compareOrdered(x, y)
+	} else if x, ok := value.(string); ok {
+		y, ok := literal.(string)
+		if !ok {
+			return false, fmt.Errorf("cannot compare string with %v", literal)
+		}
+		cmp = compareOrdered(x, y)
+	} else if value == nil {
+		// note: missing values are never ordered
+		return false, nil
+	} else {
+		return false, fmt.Errorf("operator %s requires a number or a string (got %T)", operator, value)
+	}
+	switch operator {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+func toFloat(value any) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func compareOrdered[T float64 | string](x T, y T) int {
+	if x < y {
+		return -1
+	} else if x > y {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,29 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifests
+
+import (
+	"context"
+
+	"github.com/sap/component-operator-runtime/internal/cache"
+)
+
+// Mark the generation running with ctx as not cacheable, because its result depends on inputs other than the generator's arguments
+// (such as cluster state); this is evaluated by CachingGenerator, and happens automatically if the lookup template function is used.
+func MarkUncacheable(ctx context.Context) {
+	cache.MarkUncacheable(ctx)
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package manifests
 
 import (
+	"context"
+
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/sap/component-operator-runtime/pkg/types"
@@ -26,6 +28,7 @@ import (
 type DummyGenerator struct{}
 
 var _ Generator = &DummyGenerator{}
+var _ GeneratorWithContext = &DummyGenerator{}
 
 // Create a new DummyGenerator.
 func NewDummyGenerator() (*DummyGenerator, error) {
@@ -36,3 +39,8 @@ func NewDummyGenerator() (*DummyGenerator, error) {
 func (g *DummyGenerator) Generate(namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
 	return nil, nil
 }
+
+// Generate resource descriptors.
+func (g *DummyGenerator) GenerateWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	return nil, nil
+}
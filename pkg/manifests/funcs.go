@@ -0,0 +1,91 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifests
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/component-operator-runtime/pkg/types"
+)
+
+// GeneratorFunc allows to use an ordinary function as Generator.
+type GeneratorFunc func(namespace string, name string, parameters types.Unstructurable) ([]client.Object, error)
+
+var _ Generator = GeneratorFunc(nil)
+
+// Generate resource descriptors by calling the function itself.
+func (f GeneratorFunc) Generate(namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	return f(namespace, name, parameters)
+}
+
+// GeneratorWithContextFunc allows to use an ordinary function as GeneratorWithContext.
+// If called through Generate(), the function receives context.Background() and empty reconcile information.
+type GeneratorWithContextFunc func(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error)
+
+var _ GeneratorWithContext = GeneratorWithContextFunc(nil)
+
+// Generate resource descriptors by calling the function itself (with context.Background() and empty reconcile information).
+func (f GeneratorWithContextFunc) Generate(namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	return f(context.Background(), ReconcileInfo{}, namespace, name, parameters)
+}
+
+// Generate resource descriptors by calling the function itself.
+func (f GeneratorWithContextFunc) GenerateWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	return f(ctx, info, namespace, name, parameters)
+}
+
+// ParameterTransformerFunc allows to use an ordinary function as ParameterTransformer.
+type ParameterTransformerFunc func(parameters types.Unstructurable) (types.Unstructurable, error)
+
+var _ ParameterTransformer = ParameterTransformerFunc(nil)
+
+// Transform parameters by calling the function itself.
+func (f ParameterTransformerFunc) TransformParameters(parameters types.Unstructurable) (types.Unstructurable, error) {
+	return f(parameters)
+}
+
+// ObjectTransformerFunc allows to use an ordinary function as ObjectTransformer.
+type ObjectTransformerFunc func(objects []client.Object) ([]client.Object, error)
+
+var _ ObjectTransformer = ObjectTransformerFunc(nil)
+
+// Transform objects by calling the function itself.
+func (f ObjectTransformerFunc) TransformObjects(objects []client.Object) ([]client.Object, error) {
+	return f(objects)
+}
+
+// ParameterTransformerWithContextFunc allows to use an ordinary function as ParameterTransformerWithContext.
+type ParameterTransformerWithContextFunc func(ctx context.Context, info ReconcileInfo, parameters types.Unstructurable) (types.Unstructurable, error)
+
+var _ ParameterTransformerWithContext = ParameterTransformerWithContextFunc(nil)
+
+// Transform parameters by calling the function itself.
+func (f ParameterTransformerWithContextFunc) TransformParametersWithContext(ctx context.Context, info ReconcileInfo, parameters types.Unstructurable) (types.Unstructurable, error) {
+	return f(ctx, info, parameters)
+}
+
+// ObjectTransformerWithContextFunc allows to use an ordinary function as ObjectTransformerWithContext.
+type ObjectTransformerWithContextFunc func(ctx context.Context, info ReconcileInfo, objects []client.Object) ([]client.Object, error)
+
+var _ ObjectTransformerWithContext = ObjectTransformerWithContextFunc(nil)
+
+// Transform objects by calling the function itself.
+func (f ObjectTransformerWithContextFunc) TransformObjectsWithContext(ctx context.Context, info ReconcileInfo, objects []client.Object) ([]client.Object, error) {
+	return f(ctx, info, objects)
+}
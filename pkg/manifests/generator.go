@@ -17,17 +17,26 @@ limitations under the License.
 package manifests
 
 import (
+	"context"
+
 	"github.com/pkg/errors"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/sap/component-operator-runtime/internal/cache"
 	"github.com/sap/component-operator-runtime/pkg/types"
 )
 
+var _ ReleaseAwareGenerator = &tranformableGenerator{}
+var _ NotesGenerator = &tranformableGenerator{}
+var _ GeneratorWithContext = &tranformableGenerator{}
+var _ NotesGeneratorWithContext = &tranformableGenerator{}
+
 type tranformableGenerator struct {
-	generator             Generator
-	parameterTransformers []ParameterTransformer
-	objectTransformers    []ObjectTransformer
+	generator Generator
+	// note: plain transformers are wrapped into context-aware ones
+	parameterTransformers []ParameterTransformerWithContext
+	objectTransformers    []ObjectTransformerWithContext
 }
 
 func NewGenerator(generator Generator) TransformableGenerator {
@@ -35,29 +44,91 @@ func NewGenerator(generator Generator) TransformableGenerator {
 }
 
 func (g *tranformableGenerator) WithParameterTransformer(transformer ParameterTransformer) TransformableGenerator {
+	if transformer, ok := transformer.(ParameterTransformerWithContext); ok {
+		return g.WithParameterTransformerWithContext(transformer)
+	}
+	return g.WithParameterTransformerWithContext(ParameterTransformerWithContextFunc(func(ctx context.Context, info ReconcileInfo, parameters types.Unstructurable) (types.Unstructurable, error) {
+		cache.MarkOpaque(ctx)
+		return transformer.TransformParameters(parameters)
+	}))
+}
+
+func (g *tranformableGenerator) WithObjectTransformer(transformer ObjectTransformer) TransformableGenerator {
+	if transformer, ok := transformer.(ObjectTransformerWithContext); ok {
+		return g.WithObjectTransformerWithContext(transformer)
+	}
+	return g.WithObjectTransformerWithContext(ObjectTransformerWithContextFunc(func(ctx context.Context, info ReconcileInfo, objects []client.Object) ([]client.Object, error) {
+		cache.MarkOpaque(ctx)
+		return transformer.TransformObjects(objects)
+	}))
+}
+
+func (g *tranformableGenerator) WithParameterTransformerWithContext(transformer ParameterTransformerWithContext) TransformableGenerator {
 	g.parameterTransformers = append(g.parameterTransformers, transformer)
 	return g
 }
 
-func (g *tranformableGenerator) WithObjectTransformer(transformer ObjectTransformer) TransformableGenerator {
+func (g *tranformableGenerator) WithObjectTransformerWithContext(transformer ObjectTransformerWithContext) TransformableGenerator {
 	g.objectTransformers = append(g.objectTransformers, transformer)
 	return g
 }
 
 func (g *tranformableGenerator) Generate(namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
-	for i, transformer := range g.parameterTransformers {
-		_parameters, err := transformer.TransformParameters(parameters)
-		if err != nil {
-			return nil, errors.Wrapf(err, "error calling parameter transformer (%d)", i)
-		}
-		parameters = _parameters
+	return g.generate(context.Background(), ReconcileInfo{}, false, namespace, name, parameters)
+}
+
+func (g *tranformableGenerator) GenerateRelease(namespace string, name string, parameters types.Unstructurable, release ReleaseInfo) ([]client.Object, error) {
+	return g.generate(context.Background(), ReconcileInfo{Release: &release}, false, namespace, name, parameters)
+}
+
+func (g *tranformableGenerator) GenerateWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	return g.generate(ctx, info, true, namespace, name, parameters)
+}
+
+func (g *tranformableGenerator) GenerateNotes(namespace string, name string, parameters types.Unstructurable, release ReleaseInfo) (string, error) {
+	generator, ok := g.generator.(NotesGenerator)
+	if !ok {
+		return "", nil
+	}
+	parameters, err := g.transformParameters(context.Background(), ReconcileInfo{Release: &release}, parameters)
+	if err != nil {
+		return "", err
+	}
+	return generator.GenerateNotes(namespace, name, parameters, release)
+}
+
+func (g *tranformableGenerator) GenerateNotesWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) (string, error) {
+	generator, ok := g.generator.(NotesGenerator)
+	if !ok {
+		return "", nil
+	}
+	parameters, err := g.transformParameters(ctx, info, parameters)
+	if err != nil {
+		return "", err
+	}
+	return generateNotes(ctx, generator, info, namespace, name, parameters)
+}
+
+func (g *tranformableGenerator) generate(ctx context.Context, info ReconcileInfo, withContext bool, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	parameters, err := g.transformParameters(ctx, info, parameters)
+	if err != nil {
+		return nil, err
+	}
+	var objects []client.Object
+	if generator, ok := g.generator.(GeneratorWithContext); ok && withContext {
+		objects, err = generator.GenerateWithContext(ctx, info, namespace, name, parameters)
+	} else if generator, ok := g.generator.(ReleaseAwareGenerator); ok && info.Release != nil {
+		cache.MarkOpaque(ctx)
+		objects, err = generator.GenerateRelease(namespace, name, parameters, *info.Release)
+	} else {
+		cache.MarkOpaque(ctx)
+		objects, err = g.generator.Generate(namespace, name, parameters)
 	}
-	objects, err := g.generator.Generate(namespace, name, parameters)
 	if err != nil {
 		return nil, err
 	}
 	for i, transformer := range g.objectTransformers {
-		_objects, err := transformer.TransformObjects(objects)
+		_objects, err := transformer.TransformObjectsWithContext(ctx, info, objects)
 		if err != nil {
 			return nil, errors.Wrapf(err, "error calling object transformer (%d)", i)
 		}
@@ -65,3 +136,14 @@ func (g *tranformableGenerator) Generate(namespace string, name string, paramete
 	}
 	return objects, nil
 }
+
+func (g *tranformableGenerator) transformParameters(ctx context.Context, info ReconcileInfo, parameters types.Unstructurable) (types.Unstructurable, error) {
+	for i, transformer := range g.parameterTransformers {
+		_parameters, err := transformer.TransformParametersWithContext(ctx, info, parameters)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error calling parameter transformer (%d)", i)
+		}
+		parameters = _parameters
+	}
+	return parameters, nil
+}
@@ -18,7 +18,7 @@ package manifests
 
 import (
 	"bytes"
-	"errors"
+	"context"
 	"fmt"
 	"io"
 	"io/fs"
@@ -35,7 +35,6 @@ import (
 	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/discovery"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	kyaml "sigs.k8s.io/yaml"
 
 	"github.com/sap/component-operator-runtime/internal/helm"
 	"github.com/sap/component-operator-runtime/internal/templatex"
@@ -43,23 +42,26 @@ import (
 )
 
 // HelmGenerator is a Generator implementation that basically renders a given Helm chart.
-// A few restrictions apply to the provided Helm chart: it must not contain any subcharts, some template functions are not supported,
+// A few restrictions apply to the provided Helm chart: some template functions are not supported,
 // some bultin variables are not supported, and hooks are processed in a slightly different fashion.
 type HelmGenerator struct {
 	name            string
+	client          client.Client
 	discoveryClient discovery.DiscoveryInterface
-	files           [][]byte
-	templates       []*template.Template
-	data            map[string]any
+	chart           *helm.Chart
+	// some template of the (associated) set of all templates, or nil if there are no templates
+	template  *template.Template
+	templates map[*helm.Chart][]*template.Template
+	notes     *template.Template
 }
 
-var _ Generator = &HelmGenerator{}
+var _ ReleaseAwareGenerator = &HelmGenerator{}
+var _ NotesGenerator = &HelmGenerator{}
+var _ NotesGeneratorWithContext = &HelmGenerator{}
+var _ GeneratorWithContext = &HelmGenerator{}
 
 // Create a new HelmGenerator.
 func NewHelmGenerator(name string, fsys fs.FS, chartPath string, client client.Client, discoveryClient discovery.DiscoveryInterface) (*HelmGenerator, error) {
-	g := HelmGenerator{name: name, discoveryClient: discoveryClient}
-	g.data = make(map[string]any)
-
 	if fsys == nil {
 		fsys = os.DirFS("/")
 		absoluteChartPath, err := filepath.Abs(chartPath)
@@ -69,92 +71,119 @@ func NewHelmGenerator(name string, fsys fs.FS, chartPath string, client client.C
 		chartPath = absoluteChartPath[1:]
 	}
 
-	chartRaw, err := fs.ReadFile(fsys, chartPath+"/Chart.yaml")
+	chart, err := helm.LoadChart(fsys, chartPath)
 	if err != nil {
 		return nil, err
 	}
-	g.data["Chart"] = &helm.ChartData{}
-	if err := kyaml.Unmarshal(chartRaw, g.data["Chart"]); err != nil {
-		return nil, err
-	}
-	// TODO: validate that dependencies is not set in Chart.yaml
-	// TODO: validate that ./charts does not exist
 
-	valuesRaw, err := fs.ReadFile(fsys, chartPath+"/values.yaml")
-	if err == nil {
-		g.data["Values"] = &map[string]any{}
-		if err := kyaml.Unmarshal(valuesRaw, g.data["Values"]); err != nil {
+	return newHelmGenerator(name, chart, client, discoveryClient)
+}
+
+// Create a new HelmGenerator from a packaged chart (that is, a gzipped tar archive, as created by 'helm package').
+func NewHelmGeneratorFromArchive(name string, fsys fs.FS, archivePath string, client client.Client, discoveryClient discovery.DiscoveryInterface) (*HelmGenerator, error) {
+	if fsys == nil {
+		fsys = os.DirFS("/")
+		absoluteArchivePath, err := filepath.Abs(archivePath)
+		if err != nil {
 			return nil, err
 		}
-	} else if errors.Is(err, fs.ErrNotExist) {
-		g.data["Values"] = &map[string]any{}
-	} else {
+		archivePath = absoluteArchivePath[1:]
+	}
+
+	data, err := fs.ReadFile(fsys, archivePath)
+	if err != nil {
 		return nil, err
 	}
 
-	files, err := fs.Glob(fsys, chartPath+"/crds/*.yaml")
+	return NewHelmGeneratorFromArchiveData(name, data, client, discoveryClient)
+}
+
+// Create a new HelmGenerator from the contents of a packaged chart (that is, a gzipped tar archive, as created by 'helm package').
+func NewHelmGeneratorFromArchiveData(name string, data []byte, client client.Client, discoveryClient discovery.DiscoveryInterface) (*HelmGenerator, error) {
+	chart, err := helm.LoadChartFromArchive(bytes.NewReader(data))
 	if err != nil {
-		panic("this cannot happen")
-		// because an error would occur only if the pattern is malformed, which is not the case
+		return nil, err
 	}
-	for _, file := range files {
-		raw, err := fs.ReadFile(fsys, file)
+
+	return newHelmGenerator(name, chart, client, discoveryClient)
+}
+
+// Create a new HelmGenerator from a chart stored in an OCI image layout directory (for example as created by 'oras copy --to-oci-layout').
+// The chart manifest is selected by reference (a tag or a digest); reference may be empty if the layout contains exactly one manifest.
+// The digests (and sizes) of all blobs read from the layout are verified.
+func NewHelmGeneratorFromOciLayout(name string, fsys fs.FS, layoutPath string, reference string, client client.Client, discoveryClient discovery.DiscoveryInterface) (*HelmGenerator, error) {
+	if fsys == nil {
+		fsys = os.DirFS("/")
+		absoluteLayoutPath, err := filepath.Abs(layoutPath)
 		if err != nil {
 			return nil, err
 		}
-		g.files = append(g.files, raw)
+		layoutPath = absoluteLayoutPath[1:]
 	}
 
-	includes, err := fs.Glob(fsys, chartPath+"/templates/_*")
+	chart, err := helm.LoadChartFromOciLayout(fsys, layoutPath, reference)
 	if err != nil {
-		panic("this cannot happen")
-		// because an error would occur only if the pattern is malformed, which is not the case
-	}
-	sources, err := fs.Glob(fsys, chartPath+"/templates/[^_]*.yaml")
-	if err != nil {
-		panic("this cannot happen")
-		// because an error would occur only if the pattern is malformed, which is not the case
+		return nil, err
 	}
-	if len(sources) == 0 {
-		return &g, nil
+
+	return newHelmGenerator(name, chart, client, discoveryClient)
+}
+
+func newHelmGenerator(name string, chart *helm.Chart, client client.Client, discoveryClient discovery.DiscoveryInterface) (*HelmGenerator, error) {
+	g := HelmGenerator{name: name, client: client, discoveryClient: discoveryClient, chart: chart}
+	g.templates = make(map[*helm.Chart][]*template.Template)
+
+	// note: as with helm, templates of all charts (including subcharts) are associated with each other
+	var charts []*helm.Chart
+	var collectCharts func(chart *helm.Chart)
+	collectCharts = func(chart *helm.Chart) {
+		if slices.Contains(charts, chart) {
+			return
+		}
+		charts = append(charts, chart)
+		for _, dependency := range chart.Dependencies {
+			collectCharts(dependency.Chart)
+		}
 	}
+	collectCharts(chart)
 
 	var t *template.Template
-	for _, source := range sources {
-		raw, err := fs.ReadFile(fsys, source)
-		if err != nil {
-			return nil, err
-		}
+	newTemplate := func(name string) *template.Template {
 		if t == nil {
-			t = template.New(source)
+			t = template.New(name)
 		} else {
-			t = t.New(source)
+			t = t.New(name)
 		}
 		t.Option("missingkey=zero").
 			Funcs(sprig.TxtFuncMap()).
 			Funcs(templatex.FuncMap()).
 			Funcs(templatex.FuncMapForTemplate(t)).
 			Funcs(templatex.FuncMapForClient(client))
-		if _, err := t.Parse(string(raw)); err != nil {
-			return nil, err
+		return t
+	}
+	for _, chart := range charts {
+		for _, source := range chart.Templates {
+			tmpl := newTemplate(source.Name)
+			if _, err := tmpl.Parse(string(source.Data)); err != nil {
+				return nil, err
+			}
+			g.templates[chart] = append(g.templates[chart], tmpl)
 		}
-		g.templates = append(g.templates, t)
 	}
-	for _, include := range includes {
-		raw, err := fs.ReadFile(fsys, include)
-		if err != nil {
-			return nil, err
+	for _, chart := range charts {
+		for _, include := range chart.Includes {
+			if _, err := newTemplate(include.Name).Parse(string(include.Data)); err != nil {
+				return nil, err
+			}
 		}
-		t = t.New(include)
-		t.Option("missingkey=zero").
-			Funcs(sprig.TxtFuncMap()).
-			Funcs(templatex.FuncMap()).
-			Funcs(templatex.FuncMapForTemplate(t)).
-			Funcs(templatex.FuncMapForClient(client))
-		if _, err := t.Parse(string(raw)); err != nil {
+	}
+	if chart.Notes != nil {
+		g.notes = newTemplate(chart.Notes.Name)
+		if _, err := g.notes.Parse(string(chart.Notes.Data)); err != nil {
 			return nil, err
 		}
 	}
+	g.template = t
 
 	return &g, nil
 }
@@ -177,8 +206,28 @@ func NewHelmGeneratorWithObjectTransformer(name string, fsys fs.FS, chartPath st
 	return NewGenerator(g).WithObjectTransformer(transformer), nil
 }
 
-// Generate resource descriptors.
+// Generate resource descriptors; the release is treated as an install (with revision 1).
 func (g *HelmGenerator) Generate(namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	return g.GenerateRelease(namespace, name, parameters, ReleaseInfo{IsInstall: true, Revision: 1})
+}
+
+// Generate resource descriptors for the given release; release information is exposed to the chart templates as .Release.IsInstall, .Release.IsUpgrade and .Release.Revision.
+func (g *HelmGenerator) GenerateRelease(namespace string, name string, parameters types.Unstructurable, releaseInfo ReleaseInfo) ([]client.Object, error) {
+	return g.generate(context.Background(), nil, namespace, name, parameters, releaseInfo)
+}
+
+// Generate resource descriptors, using the given context (and the reconciler's client, if passed) for lookups;
+// if no release information is passed, the release is treated as an install (with revision 1).
+func (g *HelmGenerator) GenerateWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	releaseInfo := ReleaseInfo{IsInstall: true, Revision: 1}
+	if info.Release != nil {
+		releaseInfo = *info.Release
+	}
+	return g.generate(ctx, info.Client, namespace, name, parameters, releaseInfo)
+}
+
+// generate resource descriptors; lookups use the given context and client (or the generator's client, if nil)
+func (g *HelmGenerator) generate(ctx context.Context, c client.Client, namespace string, name string, parameters types.Unstructurable, releaseInfo ReleaseInfo) ([]client.Object, error) {
 	var objects []client.Object
 
 	// TODO: this (and the according values of the annotations) should be available as constants somewhere
@@ -186,139 +235,244 @@ func (g *HelmGenerator) Generate(namespace string, name string, parameters types
 	annotationKeyUpdatePolicy := g.name + "/update-policy"
 	annotationKeyOrder := g.name + "/order"
 	annotationKeyPurgeOrder := g.name + "/purge-order"
+	annotationKeyDeleteHook := g.name + "/delete-hook"
+	annotationKeyDeleteHookPurgePolicy := g.name + "/delete-hook-purge-policy"
 
-	data := make(map[string]any)
-	for k, v := range g.data {
-		data[k] = v
-	}
-
-	capabilities, err := helm.GetCapabilities(g.discoveryClient)
+	scopedCharts, release, capabilities, err := g.prepare(namespace, name, parameters, releaseInfo)
 	if err != nil {
 		return nil, err
 	}
-	data["Capabilities"] = capabilities
 
-	data["Release"] = &helm.ReleaseData{
-		Namespace: namespace,
-		Name:      name,
-		Service:   g.name,
+	templates, err := g.cloneTemplates(ctx, c)
+	if err != nil {
+		return nil, err
 	}
 
-	data["Values"] = MergeMaps(*data["Values"].(*map[string]any), parameters.ToUnstructured())
-
-	for _, f := range g.files {
-		decoder := utilyaml.NewYAMLToJSONDecoder(bytes.NewBuffer(f))
-		for {
-			object := &unstructured.Unstructured{}
-			if err := decoder.Decode(&object.Object); err != nil {
-				if err == io.EOF {
-					break
+	for _, scopedChart := range scopedCharts {
+		for _, crd := range scopedChart.Chart.Crds {
+			decoder := utilyaml.NewYAMLToJSONDecoder(bytes.NewBuffer(crd.Data))
+			for {
+				object := &unstructured.Unstructured{}
+				if err := decoder.Decode(&object.Object); err != nil {
+					if err == io.EOF {
+						break
+					}
+					return nil, err
 				}
-				return nil, err
-			}
-			if object.Object == nil {
-				continue
+				if object.Object == nil {
+					continue
+				}
+				objects = append(objects, object)
 			}
-			objects = append(objects, object)
 		}
 	}
 
-	for _, t := range g.templates {
-		data["Template"] = &helm.TemplateData{
-			Name:     t.Name(),
-			BasePath: filepath.Dir(t.Name()),
-		}
-		var buf bytes.Buffer
-		if err := t.Execute(&buf, data); err != nil {
-			return nil, err
-		}
-		decoder := utilyaml.NewYAMLToJSONDecoder(&buf)
-		for {
-			object := &unstructured.Unstructured{}
-			if err := decoder.Decode(&object.Object); err != nil {
-				if err == io.EOF {
-					break
-				}
-				return nil, err
-			}
-			if object.Object == nil {
-				continue
-			}
-			annotations := object.GetAnnotations()
-			for key := range annotations {
-				if strings.HasPrefix(key, g.name+"/") {
-					return nil, fmt.Errorf("annotation %s must not be set (object: %s)", key, types.ObjectKeyToString(object))
-				}
-			}
-			hookMetadata, err := helm.ParseHookMetadata(object)
-			if err != nil {
+	for _, scopedChart := range scopedCharts {
+		for _, t := range g.templates[scopedChart.Chart] {
+			t = templates.Lookup(t.Name())
+			data := newHelmTemplateData(scopedChart, release, capabilities, t)
+			var buf bytes.Buffer
+			if err := t.Execute(&buf, data); err != nil {
 				return nil, err
 			}
-			if hookMetadata != nil {
-				if slices.Contains(hookMetadata.Types, helm.HookTypePreDelete) {
-					return nil, fmt.Errorf("helm hook type %s not supported (object: %s)", helm.HookTypePreDelete, types.ObjectKeyToString(object))
-				}
-				if slices.Contains(hookMetadata.Types, helm.HookTypePostDelete) {
-					return nil, fmt.Errorf("helm hook type %s not supported (object: %s)", helm.HookTypePostDelete, types.ObjectKeyToString(object))
+			decoder := utilyaml.NewYAMLToJSONDecoder(&buf)
+			for {
+				object := &unstructured.Unstructured{}
+				if err := decoder.Decode(&object.Object); err != nil {
+					if err == io.EOF {
+						break
+					}
+					return nil, err
 				}
-				hookMetadata.Types = slices.Remove(hookMetadata.Types, helm.HookTypePreRollback)
-				hookMetadata.Types = slices.Remove(hookMetadata.Types, helm.HookTypePostRollback)
-				hookMetadata.Types = slices.Remove(hookMetadata.Types, helm.HookTypeTest)
-				hookMetadata.Types = slices.Remove(hookMetadata.Types, helm.HookTypeTestSuccess)
-				if len(hookMetadata.Types) == 0 {
+				if object.Object == nil {
 					continue
 				}
-				if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyHookFailed) {
-					return nil, fmt.Errorf("helm delete policy %s is not supported (object: %s)", helm.HookDeletePolicyHookFailed, types.ObjectKeyToString(object))
+				annotations := object.GetAnnotations()
+				for key := range annotations {
+					if strings.HasPrefix(key, g.name+"/") {
+						return nil, fmt.Errorf("annotation %s must not be set (object: %s)", key, types.ObjectKeyToString(object))
+					}
 				}
-				if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyBeforeHookCreation) {
-					// TODO: use a constant
-					annotations[annotationKeyUpdatePolicy] = "recreate"
+				hookMetadata, err := helm.ParseHookMetadata(object)
+				if err != nil {
+					return nil, err
 				}
-				switch {
-				case slices.Equal(slices.Sort(hookMetadata.Types), slices.Sort([]string{helm.HookTypePreInstall})):
-					// TODO: use a constant
-					annotations[annotationKeyReconcilePolicy] = "once"
-					annotations[annotationKeyOrder] = strconv.Itoa(hookMetadata.Weight - helm.HookMaxWeight - 1)
-					if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyHookSucceeded) {
-						annotations[annotationKeyPurgeOrder] = strconv.Itoa(-1)
+				if hookMetadata != nil {
+					hookMetadata.Types = slices.Remove(hookMetadata.Types, helm.HookTypePreRollback)
+					hookMetadata.Types = slices.Remove(hookMetadata.Types, helm.HookTypePostRollback)
+					hookMetadata.Types = slices.Remove(hookMetadata.Types, helm.HookTypeTest)
+					hookMetadata.Types = slices.Remove(hookMetadata.Types, helm.HookTypeTestSuccess)
+					if len(hookMetadata.Types) == 0 {
+						continue
 					}
-				case slices.Equal(slices.Sort(hookMetadata.Types), slices.Sort([]string{helm.HookTypePostInstall})):
-					// TODO: use a constant
-					annotations[annotationKeyReconcilePolicy] = "once"
-					annotations[annotationKeyOrder] = strconv.Itoa(hookMetadata.Weight - helm.HookMinWeight + 1)
-					if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyHookSucceeded) {
-						annotations[annotationKeyPurgeOrder] = strconv.Itoa(helm.HookMaxWeight - helm.HookMinWeight + 1)
+					if slices.Contains(hookMetadata.Types, helm.HookTypePreDelete) || slices.Contains(hookMetadata.Types, helm.HookTypePostDelete) {
+						// delete hooks are handled by the reconciler when the component is deleted;
+						// they are always recreated if existing, and they cannot be combined with other hook types
+						if len(hookMetadata.Types) > 1 {
+							return nil, fmt.Errorf("unsupported helm hook type combination: %s (object: %s)", strings.Join(hookMetadata.Types, ","), types.ObjectKeyToString(object))
+						}
+						// TODO: use constants
+						annotations[annotationKeyDeleteHook] = hookMetadata.Types[0]
+						annotations[annotationKeyOrder] = strconv.Itoa(hookMetadata.Weight)
+						var purgePolicies []string
+						if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyHookSucceeded) {
+							purgePolicies = append(purgePolicies, "on-success")
+						}
+						if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyHookFailed) {
+							purgePolicies = append(purgePolicies, "on-failure")
+						}
+						if len(purgePolicies) > 0 {
+							annotations[annotationKeyDeleteHookPurgePolicy] = strings.Join(purgePolicies, ",")
+						}
+						object.SetAnnotations(annotations)
+						objects = append(objects, object)
+						continue
 					}
-				case slices.Equal(slices.Sort(hookMetadata.Types), slices.Sort([]string{helm.HookTypePreInstall, helm.HookTypePreUpgrade})):
-					// TODO: use a constant
-					annotations[annotationKeyReconcilePolicy] = "on-object-or-component-change"
-					annotations[annotationKeyOrder] = strconv.Itoa(hookMetadata.Weight - helm.HookMaxWeight - 1)
-					if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyHookSucceeded) {
-						annotations[annotationKeyPurgeOrder] = strconv.Itoa(-1)
+					if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyHookFailed) {
+						return nil, fmt.Errorf("helm delete policy %s is not supported (object: %s)", helm.HookDeletePolicyHookFailed, types.ObjectKeyToString(object))
 					}
-				case slices.Equal(slices.Sort(hookMetadata.Types), slices.Sort([]string{helm.HookTypePostInstall, helm.HookTypePostUpgrade})):
-					// TODO: use a constant
-					annotations[annotationKeyReconcilePolicy] = "on-object-or-component-change"
-					annotations[annotationKeyOrder] = strconv.Itoa(hookMetadata.Weight - helm.HookMinWeight + 1)
-					if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyHookSucceeded) {
-						annotations[annotationKeyPurgeOrder] = strconv.Itoa(helm.HookMaxWeight - helm.HookMinWeight + 1)
+					if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyBeforeHookCreation) {
+						// TODO: use a constant
+						annotations[annotationKeyUpdatePolicy] = "recreate"
 					}
-				case slices.Equal(slices.Sort(hookMetadata.Types), slices.Sort([]string{helm.HookTypePreInstall, helm.HookTypePreUpgrade, helm.HookTypePostInstall, helm.HookTypePostUpgrade})):
-					// TODO: use a constant
-					annotations[annotationKeyReconcilePolicy] = "on-object-or-component-change"
-					annotations[annotationKeyOrder] = strconv.Itoa(hookMetadata.Weight - helm.HookMaxWeight - 1)
-					if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyHookSucceeded) {
-						annotations[annotationKeyPurgeOrder] = strconv.Itoa(helm.HookMaxWeight - helm.HookMinWeight + 1)
+					switch {
+					case slices.Equal(slices.Sort(hookMetadata.Types), slices.Sort([]string{helm.HookTypePreInstall})):
+						// TODO: use a constant
+						annotations[annotationKeyReconcilePolicy] = "once"
+						annotations[annotationKeyOrder] = strconv.Itoa(hookMetadata.Weight - helm.HookMaxWeight - 1)
+						if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyHookSucceeded) {
+							annotations[annotationKeyPurgeOrder] = strconv.Itoa(-1)
+						}
+					case slices.Equal(slices.Sort(hookMetadata.Types), slices.Sort([]string{helm.HookTypePostInstall})):
+						// TODO: use a constant
+						annotations[annotationKeyReconcilePolicy] = "once"
+						annotations[annotationKeyOrder] = strconv.Itoa(hookMetadata.Weight - helm.HookMinWeight + 1)
+						if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyHookSucceeded) {
+							annotations[annotationKeyPurgeOrder] = strconv.Itoa(helm.HookMaxWeight - helm.HookMinWeight + 1)
+						}
+					case slices.Equal(slices.Sort(hookMetadata.Types), slices.Sort([]string{helm.HookTypePreInstall, helm.HookTypePreUpgrade})):
+						// TODO: use a constant
+						annotations[annotationKeyReconcilePolicy] = "on-object-or-component-change"
+						annotations[annotationKeyOrder] = strconv.Itoa(hookMetadata.Weight - helm.HookMaxWeight - 1)
+						if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyHookSucceeded) {
+							annotations[annotationKeyPurgeOrder] = strconv.Itoa(-1)
+						}
+					case slices.Equal(slices.Sort(hookMetadata.Types), slices.Sort([]string{helm.HookTypePostInstall, helm.HookTypePostUpgrade})):
+						// TODO: use a constant
+						annotations[annotationKeyReconcilePolicy] = "on-object-or-component-change"
+						annotations[annotationKeyOrder] = strconv.Itoa(hookMetadata.Weight - helm.HookMinWeight + 1)
+						if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyHookSucceeded) {
+							annotations[annotationKeyPurgeOrder] = strconv.Itoa(helm.HookMaxWeight - helm.HookMinWeight + 1)
+						}
+					case slices.Equal(slices.Sort(hookMetadata.Types), slices.Sort([]string{helm.HookTypePreInstall, helm.HookTypePreUpgrade, helm.HookTypePostInstall, helm.HookTypePostUpgrade})):
+						// TODO: use a constant
+						annotations[annotationKeyReconcilePolicy] = "on-object-or-component-change"
+						annotations[annotationKeyOrder] = strconv.Itoa(hookMetadata.Weight - helm.HookMaxWeight - 1)
+						if slices.Contains(hookMetadata.DeletePolicies, helm.HookDeletePolicyHookSucceeded) {
+							annotations[annotationKeyPurgeOrder] = strconv.Itoa(helm.HookMaxWeight - helm.HookMinWeight + 1)
+						}
+					default:
+						return nil, fmt.Errorf("unsupported helm hook type combination: %s (object: %s)", strings.Join(hookMetadata.Types, ","), types.ObjectKeyToString(object))
 					}
-				default:
-					return nil, fmt.Errorf("unsupported helm hook type combination: %s (object: %s)", strings.Join(hookMetadata.Types, ","), types.ObjectKeyToString(object))
+					object.SetAnnotations(annotations)
 				}
-				object.SetAnnotations(annotations)
+				objects = append(objects, object)
 			}
-			objects = append(objects, object)
 		}
 	}
 
 	return objects, nil
 }
+
+// Render the notes (that is, templates/NOTES.txt) of the top-level chart; returns an empty string if the chart has no notes.
+// As with helm, notes of subcharts are not rendered.
+func (g *HelmGenerator) GenerateNotes(namespace string, name string, parameters types.Unstructurable, releaseInfo ReleaseInfo) (string, error) {
+	return g.generateNotes(context.Background(), nil, namespace, name, parameters, releaseInfo)
+}
+
+// Render the notes, using the given context (and the reconciler's client, if passed) for lookups;
+// if no release information is passed, the release is treated as an install (with revision 1).
+func (g *HelmGenerator) GenerateNotesWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) (string, error) {
+	releaseInfo := ReleaseInfo{IsInstall: true, Revision: 1}
+	if info.Release != nil {
+		releaseInfo = *info.Release
+	}
+	return g.generateNotes(ctx, info.Client, namespace, name, parameters, releaseInfo)
+}
+
+// render notes; lookups use the given context and client (or the generator's client, if nil)
+func (g *HelmGenerator) generateNotes(ctx context.Context, c client.Client, namespace string, name string, parameters types.Unstructurable, releaseInfo ReleaseInfo) (string, error) {
+	if g.notes == nil {
+		return "", nil
+	}
+
+	scopedCharts, release, capabilities, err := g.prepare(namespace, name, parameters, releaseInfo)
+	if err != nil {
+		return "", err
+	}
+
+	templates, err := g.cloneTemplates(ctx, c)
+	if err != nil {
+		return "", err
+	}
+	notes := templates.Lookup(g.notes.Name())
+
+	var buf bytes.Buffer
+	// note: the first scoped chart is always the top-level chart
+	if err := notes.Execute(&buf, newHelmTemplateData(scopedCharts[0], release, capabilities, notes)); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// clone the templates, such that lookups are executed with the given context and client (or the generator's client, if nil)
+func (g *HelmGenerator) cloneTemplates(ctx context.Context, client client.Client) (*template.Template, error) {
+	if g.template == nil {
+		return nil, nil
+	}
+	if client == nil {
+		client = g.client
+	}
+	return templatex.CloneWithContext(ctx, g.template, client)
+}
+
+// compute the scoped (enabled) charts with their values, and the release and capabilities builtins
+func (g *HelmGenerator) prepare(namespace string, name string, parameters types.Unstructurable, releaseInfo ReleaseInfo) ([]*helm.ScopedChart, *helm.ReleaseData, *helm.CapabilitiesData, error) {
+	capabilities, err := helm.GetCapabilities(g.discoveryClient)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	release := &helm.ReleaseData{
+		Namespace: namespace,
+		Name:      name,
+		Service:   g.name,
+		IsInstall: releaseInfo.IsInstall,
+		IsUpgrade: releaseInfo.IsUpgrade,
+		Revision:  int(releaseInfo.Revision),
+	}
+
+	scopedCharts, err := helm.ProcessValues(g.chart, parameters.ToUnstructured())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := helm.ValidateValues(scopedCharts); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return scopedCharts, release, capabilities, nil
+}
+
+func newHelmTemplateData(scopedChart *helm.ScopedChart, release *helm.ReleaseData, capabilities *helm.CapabilitiesData, t *template.Template) map[string]any {
+	return map[string]any{
+		"Values":       scopedChart.Values,
+		"Chart":        scopedChart.Metadata,
+		"Files":        helm.NewFiles(scopedChart.Chart.Files),
+		"Release":      release,
+		"Capabilities": capabilities,
+		"Template": &helm.TemplateData{
+			Name:     t.Name(),
+			BasePath: scopedChart.Chart.FullName + "/templates",
+		},
+	}
+}
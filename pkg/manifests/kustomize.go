@@ -18,6 +18,7 @@ package manifests
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"io/fs"
 	"os"
@@ -29,26 +30,59 @@ import (
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/kustomize/api/krusty"
 	kustypes "sigs.k8s.io/kustomize/api/types"
 	kustfsys "sigs.k8s.io/kustomize/kyaml/filesys"
+	kyaml "sigs.k8s.io/yaml"
 
+	"github.com/sap/component-operator-runtime/internal/helm"
 	"github.com/sap/component-operator-runtime/internal/templatex"
 	"github.com/sap/component-operator-runtime/pkg/types"
 )
 
 // KustomizeGenerator is a Generator implementation that basically renders a given Kustomization.
 type KustomizeGenerator struct {
-	kustomizer *krusty.Kustomizer
-	templates  []*template.Template
+	client          client.Client
+	discoveryClient discovery.DiscoveryInterface
+	options         KustomizeGeneratorOptions
+	kustomizer      *krusty.Kustomizer
+	files           map[string][]byte
+	templates       []*template.Template
 }
 
-var _ Generator = &KustomizeGenerator{}
+var _ GeneratorWithContext = &KustomizeGenerator{}
+
+// KustomizeGeneratorOptions allows to tweak the behavior of the KustomizeGenerator.
+type KustomizeGeneratorOptions struct {
+	// If not empty, only files with this suffix will be considered as templates; other files are used as they are.
+	TemplateSuffix string
+	// Path (relative to the kustomization path) of the kustomization to be built; if empty, the kustomization path itself is built.
+	// This allows to keep bases, components and (multi-level) overlays side by side below the kustomization path.
+	OverlayPath string
+	// Load restrictions passed to kustomize; defaults to kustypes.LoadRestrictionsNone.
+	LoadRestrictions kustypes.LoadRestrictions
+	// If true, the namespace passed to Generate() will be set as namespace of the kustomization.
+	SetNamespace bool
+	// If true, the name passed to Generate(), followed by a dash, will be set as name prefix of the kustomization.
+	SetNamePrefix bool
+	// If true, a dash, followed by the name passed to Generate(), will be set as name suffix of the kustomization.
+	SetNameSuffix bool
+}
+
+// name of the directory holding the rendered kustomization in the in-memory filesystem (if it is wrapped)
+const kustomizationBaseDir = "base"
 
 // Create a new KustomizeGenerator.
 func NewKustomizeGenerator(fsys fs.FS, kustomizationPath string, templateSuffix string, client client.Client) (*KustomizeGenerator, error) {
-	g := KustomizeGenerator{}
+	return NewKustomizeGeneratorWithOptions(fsys, kustomizationPath, client, nil, KustomizeGeneratorOptions{TemplateSuffix: templateSuffix})
+}
+
+// Create a new KustomizeGenerator with the given options.
+// If discoveryClient is nil, the .Capabilities builtin will not be available in templates.
+func NewKustomizeGeneratorWithOptions(fsys fs.FS, kustomizationPath string, client client.Client, discoveryClient discovery.DiscoveryInterface, options KustomizeGeneratorOptions) (*KustomizeGenerator, error) {
+	g := KustomizeGenerator{client: client, discoveryClient: discoveryClient, options: options, files: make(map[string][]byte)}
 
 	if fsys == nil {
 		fsys = os.DirFS("/")
@@ -59,11 +93,15 @@ func NewKustomizeGenerator(fsys fs.FS, kustomizationPath string, templateSuffix
 		kustomizationPath = absoluteKustomizationPath[1:]
 	}
 
-	options := &krusty.Options{
-		LoadRestrictions: kustypes.LoadRestrictionsNone,
+	loadRestrictions := options.LoadRestrictions
+	if loadRestrictions == kustypes.LoadRestrictionsUnknown {
+		loadRestrictions = kustypes.LoadRestrictionsNone
+	}
+	kustomizerOptions := &krusty.Options{
+		LoadRestrictions: loadRestrictions,
 		PluginConfig:     kustypes.DisabledPluginConfig(),
 	}
-	g.kustomizer = krusty.MakeKustomizer(options)
+	g.kustomizer = krusty.MakeKustomizer(kustomizerOptions)
 
 	var t *template.Template
 	if err := fs.WalkDir(
@@ -76,9 +114,6 @@ func NewKustomizeGenerator(fsys fs.FS, kustomizationPath string, templateSuffix
 			if !dirEntry.Type().IsRegular() {
 				return nil
 			}
-			if !strings.HasSuffix(path, templateSuffix) {
-				return nil
-			}
 			raw, err := fs.ReadFile(fsys, path)
 			if err != nil {
 				return err
@@ -88,6 +123,10 @@ func NewKustomizeGenerator(fsys fs.FS, kustomizationPath string, templateSuffix
 				// TODO: is it ok to panic here in case of error ?
 				panic(err)
 			}
+			if !strings.HasSuffix(path, options.TemplateSuffix) {
+				g.files[name] = raw
+				return nil
+			}
 			if t == nil {
 				t = template.New(name)
 			} else {
@@ -131,22 +170,97 @@ func NewKustomizeGeneratorWithObjectTransformer(fsys fs.FS, kustomizationPath st
 
 // Generate resource descriptors.
 func (g *KustomizeGenerator) Generate(namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	return g.generate(context.Background(), nil, namespace, name, parameters)
+}
+
+// Generate resource descriptors, using the given context (and the reconciler's client, if passed) for lookups.
+func (g *KustomizeGenerator) GenerateWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	return g.generate(ctx, info.Client, namespace, name, parameters)
+}
+
+// generate resource descriptors; lookups use the given context and client (or the generator's client, if nil)
+func (g *KustomizeGenerator) generate(ctx context.Context, c client.Client, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
 	var objects []client.Object
 
-	data := parameters.ToUnstructured()
+	// note: for compatibility reasons, the parameters are also available at top level (but the builtins take precedence)
+	values := parameters.ToUnstructured()
+	data := make(map[string]any)
+	for key, value := range values {
+		data[key] = value
+	}
+	data["Values"] = values
+	data["Release"] = &helm.ReleaseData{
+		Namespace: namespace,
+		Name:      name,
+	}
+	if g.discoveryClient != nil {
+		capabilities, err := helm.GetCapabilities(g.discoveryClient)
+		if err != nil {
+			return nil, err
+		}
+		data["Capabilities"] = capabilities
+	}
+
 	fsys := kustfsys.MakeFsInMemory()
 
-	for _, t := range g.templates {
+	// if namespace or name prefix/suffix are to be set, the rendered kustomization is wrapped into an additional kustomization
+	baseDir := "/"
+	kustomizationDir := filepath.Join("/", g.options.OverlayPath)
+	wrap := g.options.SetNamespace || g.options.SetNamePrefix || g.options.SetNameSuffix
+	if wrap {
+		baseDir = "/" + kustomizationBaseDir
+		kustomizationDir = "/"
+	}
+
+	for name, raw := range g.files {
+		if err := fsys.WriteFile(filepath.Join(baseDir, name), raw); err != nil {
+			return nil, err
+		}
+	}
+	if c == nil {
+		c = g.client
+	}
+	templates, err := cloneTemplates(ctx, g.templates, c)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
 		var buf bytes.Buffer
 		if err := t.Execute(&buf, data); err != nil {
 			return nil, err
 		}
-		if err := fsys.WriteFile(t.Name(), buf.Bytes()); err != nil {
+		if err := fsys.WriteFile(filepath.Join(baseDir, t.Name()), buf.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	if wrap {
+		kustomization := &kustypes.Kustomization{
+			TypeMeta: kustypes.TypeMeta{
+				APIVersion: kustypes.KustomizationVersion,
+				Kind:       kustypes.KustomizationKind,
+			},
+			Resources: []string{filepath.Join(kustomizationBaseDir, g.options.OverlayPath)},
+		}
+		if g.options.SetNamespace {
+			kustomization.Namespace = namespace
+		}
+		if g.options.SetNamePrefix {
+			kustomization.NamePrefix = name + "-"
+		}
+		if g.options.SetNameSuffix {
+			kustomization.NameSuffix = "-" + name
+		}
+		raw, err := kyaml.Marshal(kustomization)
+		if err != nil {
+			return nil, err
+		}
+		if err := fsys.WriteFile("/kustomization.yaml", raw); err != nil {
 			return nil, err
 		}
 	}
 
-	resmap, err := g.kustomizer.Run(fsys, "/")
+	resmap, err := g.kustomizer.Run(fsys, kustomizationDir)
 	if err != nil {
 		return nil, err
 	}
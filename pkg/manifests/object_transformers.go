@@ -0,0 +1,315 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/sap/component-operator-runtime/pkg/types"
+)
+
+// ObjectTransformerFunc variant used by the transformers in this package; since they are deterministic, it implements ObjectTransformerWithContext as well,
+// such that using them does not render results uncacheable (see CachingGenerator).
+type deterministicObjectTransformerFunc func(objects []client.Object) ([]client.Object, error)
+
+var _ ObjectTransformer = deterministicObjectTransformerFunc(nil)
+var _ ObjectTransformerWithContext = deterministicObjectTransformerFunc(nil)
+
+func (f deterministicObjectTransformerFunc) TransformObjects(objects []client.Object) ([]client.Object, error) {
+	return f(objects)
+}
+
+func (f deterministicObjectTransformerFunc) TransformObjectsWithContext(ctx context.Context, info ReconcileInfo, objects []client.Object) ([]client.Object, error) {
+	return f(objects)
+}
+
+// Create an ObjectTransformer which adds the given labels to all objects (existing labels with the same keys are overwritten).
+// Note that pod templates and selectors of workloads are not touched.
+func NewLabelTransformer(labels map[string]string) ObjectTransformer {
+	return deterministicObjectTransformerFunc(func(objects []client.Object) ([]client.Object, error) {
+		for _, object := range objects {
+			object.SetLabels(mergeStringMaps(object.GetLabels(), labels))
+		}
+		return objects, nil
+	})
+}
+
+// Create an ObjectTransformer which adds the given annotations to all objects (existing annotations with the same keys are overwritten).
+// Note that pod templates of workloads are not touched.
+func NewAnnotationTransformer(annotations map[string]string) ObjectTransformer {
+	return deterministicObjectTransformerFunc(func(objects []client.Object) ([]client.Object, error) {
+		for _, object := range objects {
+			object.SetAnnotations(mergeStringMaps(object.GetAnnotations(), annotations))
+		}
+		return objects, nil
+	})
+}
+
+// Create an ObjectTransformer which moves all namespaced objects into the given namespace.
+// If mapper is nil, only objects which have a namespace set are considered namespaced; otherwise the scope
+// of objects without namespace is looked up through the mapper (objects of unknown types are left untouched); in that case,
+// typed objects without type information must be known to the client-go scheme.
+// Namespaces referenced in the objects' specs (for example in the subjects of role bindings) are not adjusted.
+func NewNamespaceTransformer(namespace string, mapper meta.RESTMapper) ObjectTransformer {
+	return deterministicObjectTransformerFunc(func(objects []client.Object) ([]client.Object, error) {
+		for _, object := range objects {
+			if object.GetNamespace() == "" {
+				if mapper == nil {
+					continue
+				}
+				gvk, err := getGroupVersionKind(object, clientgoscheme.Scheme)
+				if err != nil {
+					return nil, errors.Wrapf(err, "error determining type of object %s", types.ObjectKeyToString(object))
+				}
+				mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+				if meta.IsNoMatchError(err) {
+					continue
+				}
+				if err != nil {
+					return nil, errors.Wrapf(err, "error getting rest mapping for object %s", types.ObjectKeyToString(object))
+				}
+				if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+					continue
+				}
+			}
+			object.SetNamespace(namespace)
+		}
+		return objects, nil
+	})
+}
+
+// Image override, as used by NewImageTransformer().
+type ImageOverride struct {
+	// Name of the container (or init container) to which this override applies; if empty, the override applies to all containers.
+	ContainerName string
+	// Repository (that is, the image name without tag or digest); if empty, the repository is not changed.
+	Repository string
+	// Tag; if not empty, the tag (and digest) of the image is replaced.
+	Tag string
+	// Digest (such as sha256:...); if not empty, the tag (and digest) of the image is replaced; takes precedence over Tag.
+	Digest string
+}
+
+// Create an ObjectTransformer which overrides the images of containers (and init containers) of all workloads.
+// If multiple overrides match a container, they are applied in the given order.
+func NewImageTransformer(overrides ...ImageOverride) ObjectTransformer {
+	return NewPodTemplateTransformer(func(object client.Object, metadata map[string]any, spec map[string]any) error {
+		return visitContainers(spec, func(container map[string]any) error {
+			name, _ := container["name"].(string)
+			image, _ := container["image"].(string)
+			for _, override := range overrides {
+				if override.ContainerName != "" && override.ContainerName != name {
+					continue
+				}
+				repository, tag, digest := parseImage(image)
+				if override.Repository != "" {
+					repository = override.Repository
+				}
+				if override.Digest != "" {
+					tag = ""
+					digest = override.Digest
+				} else if override.Tag != "" {
+					tag = override.Tag
+					digest = ""
+				}
+				image = formatImage(repository, tag, digest)
+			}
+			if image != "" {
+				container["image"] = image
+			}
+			return nil
+		})
+	})
+}
+
+// Create an ObjectTransformer which adds the given image pull secrets to all workloads (secrets already referenced are not added again).
+func NewImagePullSecretsTransformer(secretNames ...string) ObjectTransformer {
+	return NewPodTemplateTransformer(func(object client.Object, metadata map[string]any, spec map[string]any) error {
+		imagePullSecrets, _ := spec["imagePullSecrets"].([]any)
+		for _, secretName := range secretNames {
+			found := false
+			for _, imagePullSecret := range imagePullSecrets {
+				if imagePullSecret, ok := imagePullSecret.(map[string]any); ok && imagePullSecret["name"] == secretName {
+					found = true
+					break
+				}
+			}
+			if !found {
+				imagePullSecrets = append(imagePullSecrets, map[string]any{"name": secretName})
+			}
+		}
+		if len(imagePullSecrets) > 0 {
+			spec["imagePullSecrets"] = imagePullSecrets
+		}
+		return nil
+	})
+}
+
+// Function operating on the pod template of a workload, as used by NewPodTemplateTransformer().
+// The function receives the (unstructured) metadata and spec of the pod template, and may modify them in place.
+type PodTemplateTransformerFunc func(object client.Object, metadata map[string]any, spec map[string]any) error
+
+// Create an ObjectTransformer which applies the given function to the pod templates of all workloads; here,
+// workloads are Deployments, StatefulSets, DaemonSets, ReplicaSets, Jobs, CronJobs (where the pod template of the job template is used),
+// and Pods (where the pod itself is passed). Other objects are left untouched.
+// Typed objects without type information are recognized through the client-go scheme.
+// The given function is assumed to be deterministic (in particular, it should not depend on the cluster state).
+func NewPodTemplateTransformer(f PodTemplateTransformerFunc) ObjectTransformer {
+	return deterministicObjectTransformerFunc(func(objects []client.Object) ([]client.Object, error) {
+		for _, object := range objects {
+			var path []string
+			gvk, err := getGroupVersionKind(object, clientgoscheme.Scheme)
+			if err != nil {
+				if runtime.IsNotRegisteredError(err) {
+					// note: typed objects not known to the client-go scheme cannot be workloads
+					continue
+				}
+				return nil, err
+			}
+			switch {
+			case gvk.Group == "apps" && (gvk.Kind == "Deployment" || gvk.Kind == "StatefulSet" || gvk.Kind == "DaemonSet" || gvk.Kind == "ReplicaSet"),
+				gvk.Group == "batch" && gvk.Kind == "Job":
+				path = []string{"spec", "template"}
+			case gvk.Group == "batch" && gvk.Kind == "CronJob":
+				path = []string{"spec", "jobTemplate", "spec", "template"}
+			case gvk.Group == "" && gvk.Kind == "Pod":
+				path = []string{}
+			default:
+				continue
+			}
+			if err := transformUnstructured(object, func(content map[string]any) error {
+				template := content
+				for _, key := range path {
+					value, ok := template[key].(map[string]any)
+					if !ok {
+						value = make(map[string]any)
+						template[key] = value
+					}
+					template = value
+				}
+				metadata, ok := template["metadata"].(map[string]any)
+				if !ok {
+					metadata = make(map[string]any)
+					template["metadata"] = metadata
+				}
+				spec, ok := template["spec"].(map[string]any)
+				if !ok {
+					spec = make(map[string]any)
+					template["spec"] = spec
+				}
+				return f(object, metadata, spec)
+			}); err != nil {
+				return nil, errors.Wrapf(err, "error transforming pod template of object %s", types.ObjectKeyToString(object))
+			}
+		}
+		return objects, nil
+	})
+}
+
+// return the type information of the given object; if not set (as usual for typed objects), it is looked up in the given scheme
+func getGroupVersionKind(object client.Object, scheme *runtime.Scheme) (schema.GroupVersionKind, error) {
+	gvk := object.GetObjectKind().GroupVersionKind()
+	if gvk.Kind != "" {
+		return gvk, nil
+	}
+	if _, ok := object.(*unstructured.Unstructured); ok {
+		return schema.GroupVersionKind{}, fmt.Errorf("object %s is missing type information", types.ObjectKeyToString(object))
+	}
+	return apiutil.GVKForObject(object, scheme)
+}
+
+// call f on the unstructured content of object; typed objects are converted back after f returned
+func transformUnstructured(object client.Object, f func(content map[string]any) error) error {
+	if object, ok := object.(*unstructured.Unstructured); ok {
+		return f(object.Object)
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return err
+	}
+	if err := f(content); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(content, object)
+}
+
+// call f on all containers and init containers of the given (unstructured) pod spec
+func visitContainers(spec map[string]any, f func(container map[string]any) error) error {
+	for _, key := range []string{"initContainers", "containers"} {
+		containers, _ := spec[key].([]any)
+		for _, container := range containers {
+			if container, ok := container.(map[string]any); ok {
+				if err := f(container); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// split an image reference into repository, tag and digest
+func parseImage(image string) (string, string, string) {
+	repository, tag, digest := image, "", ""
+	if i := strings.Index(repository, "@"); i >= 0 {
+		digest = repository[i+1:]
+		repository = repository[:i]
+	}
+	// note: a colon before the last slash separates the registry port, not the tag
+	if i := strings.LastIndex(repository, ":"); i >= 0 && i > strings.LastIndex(repository, "/") {
+		tag = repository[i+1:]
+		repository = repository[:i]
+	}
+	return repository, tag, digest
+}
+
+func formatImage(repository string, tag string, digest string) string {
+	image := repository
+	if tag != "" {
+		image += ":" + tag
+	}
+	if digest != "" {
+		image += "@" + digest
+	}
+	return image
+}
+
+func mergeStringMaps(x map[string]string, y map[string]string) map[string]string {
+	if len(y) == 0 {
+		return x
+	}
+	result := make(map[string]string, len(x)+len(y))
+	for k, v := range x {
+		result[k] = v
+	}
+	for k, v := range y {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	kyaml "sigs.k8s.io/yaml"
+
+	"github.com/sap/component-operator-runtime/pkg/types"
+)
+
+// Patch type.
+type PatchType string
+
+const (
+	// Strategic merge patch; for types not known to the scheme, the patch is applied as JSON merge patch.
+	PatchTypeStrategicMerge PatchType = "strategic-merge"
+	// JSON merge patch (RFC 7386).
+	PatchTypeMerge PatchType = "merge"
+	// JSON patch (RFC 6902).
+	PatchTypeJSON PatchType = "json"
+)
+
+// Selects the objects a patch is applied to; empty (or nil) fields match all objects.
+type PatchTarget struct {
+	// API group; if nil, objects of all groups match; if set to the empty string, only objects of the core group match.
+	Group   *string `json:"group,omitempty"`
+	Version string  `json:"version,omitempty"`
+	Kind    string  `json:"kind,omitempty"`
+	// Namespace; compared with the namespace the object will be deployed to (that is, for namespaced objects without namespace,
+	// the deployment namespace of the component, if the transformer is called through TransformObjectsWithContext()).
+	Namespace     string `json:"namespace,omitempty"`
+	Name          string `json:"name,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty"`
+}
+
+// Patch, as used by NewPatchTransformer().
+type Patch struct {
+	// Target objects.
+	Target PatchTarget `json:"target,omitempty"`
+	// Patch type; defaults to PatchTypeStrategicMerge.
+	Type PatchType `json:"type,omitempty"`
+	// The patch itself (as YAML or JSON); for JSON patches, this must be a list of operations.
+	Patch string `json:"patch"`
+}
+
+// PatchTransformer is an ObjectTransformer which applies a list of patches to the matching objects.
+type PatchTransformer struct {
+	scheme  *runtime.Scheme
+	patches []*compiledPatch
+}
+
+type compiledPatch struct {
+	index         int
+	target        PatchTarget
+	labelSelector labels.Selector
+	patchType     PatchType
+	patch         []byte
+	jsonPatch     jsonpatch.Patch
+}
+
+var _ ObjectTransformer = &PatchTransformer{}
+var _ ObjectTransformerWithContext = &PatchTransformer{}
+
+// Create a new PatchTransformer. Patches are applied in the given order. The scheme is used to look up the types
+// needed for strategic merge patches; if nil, the client-go scheme (containing the Kubernetes builtin types) is used.
+func NewPatchTransformer(scheme *runtime.Scheme, patches ...Patch) (*PatchTransformer, error) {
+	if scheme == nil {
+		scheme = clientgoscheme.Scheme
+	}
+	t := &PatchTransformer{scheme: scheme}
+	for i, patch := range patches {
+		p := &compiledPatch{
+			index:     i,
+			target:    patch.Target,
+			patchType: patch.Type,
+		}
+		if p.patchType == "" {
+			p.patchType = PatchTypeStrategicMerge
+		}
+		if patch.Target.LabelSelector != "" {
+			selector, err := labels.Parse(patch.Target.LabelSelector)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid label selector in patch (%d)", i)
+			}
+			p.labelSelector = selector
+		}
+		rawPatch, err := kyaml.YAMLToJSON([]byte(patch.Patch))
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing patch (%d)", i)
+		}
+		switch p.patchType {
+		case PatchTypeStrategicMerge, PatchTypeMerge:
+			var m map[string]any
+			if err := json.Unmarshal(rawPatch, &m); err != nil {
+				return nil, errors.Wrapf(err, "invalid patch (%d); must be an object", i)
+			}
+			p.patch = rawPatch
+		case PatchTypeJSON:
+			jsonPatch, err := jsonpatch.DecodePatch(rawPatch)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid patch (%d); must be a list of operations", i)
+			}
+			p.jsonPatch = jsonPatch
+		default:
+			return nil, fmt.Errorf("invalid patch type in patch (%d): %s", i, p.patchType)
+		}
+		t.patches = append(t.patches, p)
+	}
+	return t, nil
+}
+
+// Apply the patches to the given objects; objects without namespace are considered to be in no namespace.
+func (t *PatchTransformer) TransformObjects(objects []client.Object) ([]client.Object, error) {
+	return t.transformObjects(ReconcileInfo{}, objects)
+}
+
+// Apply the patches to the given objects; namespaced objects without namespace are considered to be in the deployment namespace
+// of the passed component (types unknown to the cluster are assumed to be namespaced).
+func (t *PatchTransformer) TransformObjectsWithContext(ctx context.Context, info ReconcileInfo, objects []client.Object) ([]client.Object, error) {
+	return t.transformObjects(info, objects)
+}
+
+func (t *PatchTransformer) transformObjects(info ReconcileInfo, objects []client.Object) ([]client.Object, error) {
+	for i, object := range objects {
+		gvk, err := getGroupVersionKind(object, t.scheme)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error determining type of object %s", types.ObjectKeyToString(object))
+		}
+		namespace := object.GetNamespace()
+		if namespace == "" && info.Component != nil && info.Client != nil && t.needsNamespace() {
+			namespaced, err := isNamespaced(info.Client.RESTMapper(), gvk)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error getting rest mapping for object %s", types.ObjectKeyToString(object))
+			}
+			if namespaced {
+				namespace = info.Component.GetDeploymentNamespace()
+			}
+		}
+		var matchingPatches []*compiledPatch
+		for _, patch := range t.patches {
+			if patch.matches(object, gvk, namespace) {
+				matchingPatches = append(matchingPatches, patch)
+			}
+		}
+		if len(matchingPatches) == 0 {
+			continue
+		}
+		raw, err := json.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		for _, patch := range matchingPatches {
+			raw, err = t.apply(gvk, patch, raw)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error applying patch (%d) to object %s", patch.index, types.ObjectKeyToString(object))
+			}
+		}
+		var patchedObject client.Object
+		if _, ok := object.(*unstructured.Unstructured); ok {
+			var content map[string]any
+			// note: utiljson decodes integers as int64 (as required by unstructured objects)
+			if err := utiljson.Unmarshal(raw, &content); err != nil {
+				return nil, err
+			}
+			patchedObject = &unstructured.Unstructured{Object: content}
+		} else {
+			patchedObject = reflect.New(reflect.TypeOf(object).Elem()).Interface().(client.Object)
+			if err := json.Unmarshal(raw, patchedObject); err != nil {
+				return nil, err
+			}
+		}
+		objects[i] = patchedObject
+	}
+	return objects, nil
+}
+
+func (t *PatchTransformer) apply(gvk schema.GroupVersionKind, patch *compiledPatch, raw []byte) ([]byte, error) {
+	switch patch.patchType {
+	case PatchTypeJSON:
+		return patch.jsonPatch.Apply(raw)
+	case PatchTypeStrategicMerge:
+		if dataStruct, err := t.scheme.New(gvk); err == nil {
+			return strategicpatch.StrategicMergePatch(raw, patch.patch, dataStruct)
+		} else if !runtime.IsNotRegisteredError(err) {
+			return nil, err
+		}
+		// note: fall back to merge patch for types not known to the scheme
+		return jsonpatch.MergePatch(raw, patch.patch)
+	default:
+		return jsonpatch.MergePatch(raw, patch.patch)
+	}
+}
+
+// check if some patch selects objects by namespace
+func (t *PatchTransformer) needsNamespace() bool {
+	for _, patch := range t.patches {
+		if patch.target.Namespace != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// check if the given type is namespaced; types unknown to the mapper are assumed to be namespaced
+func isNamespaced(mapper meta.RESTMapper, gvk schema.GroupVersionKind) (bool, error) {
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
+
+// check if the patch matches the given object (having the given type and effective namespace)
+func (p *compiledPatch) matches(object client.Object, gvk schema.GroupVersionKind, namespace string) bool {
+	if p.target.Group != nil && *p.target.Group != gvk.Group {
+		return false
+	}
+	if p.target.Version != "" && p.target.Version != gvk.Version {
+		return false
+	}
+	if p.target.Kind != "" && p.target.Kind != gvk.Kind {
+		return false
+	}
+	if p.target.Namespace != "" && p.target.Namespace != namespace {
+		return false
+	}
+	if p.target.Name != "" && p.target.Name != object.GetName() {
+		return false
+	}
+	if p.labelSelector != nil && !p.labelSelector.Matches(labels.Set(object.GetLabels())) {
+		return false
+	}
+	return true
+}
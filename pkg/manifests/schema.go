@@ -0,0 +1,231 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	apiservervalidation "k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	kyaml "sigs.k8s.io/yaml"
+
+	"github.com/sap/component-operator-runtime/pkg/types"
+)
+
+// SchemaParameterTransformer is a ParameterTransformer which defaults and validates parameters against
+// an OpenAPI v3 schema, with the semantics used by the Kubernetes API server for custom resources.
+// The schema must be structural (as required for custom resource definitions). Unknown fields are not pruned.
+type SchemaParameterTransformer struct {
+	structural *structuralschema.Structural
+	validator  *validate.SchemaValidator
+}
+
+// ParameterValidationError is returned by SchemaParameterTransformer if the parameters do not match the schema;
+// field paths are rooted at 'spec'.
+type ParameterValidationError struct {
+	Errors field.ErrorList
+}
+
+func (e *ParameterValidationError) Error() string {
+	return "parameters do not match schema: " + e.Errors.ToAggregate().Error()
+}
+
+var _ ParameterTransformer = &SchemaParameterTransformer{}
+var _ ParameterTransformerWithContext = &SchemaParameterTransformer{}
+
+// Create a new SchemaParameterTransformer from the given schema.
+func NewSchemaParameterTransformer(schema *apiextensionsv1.JSONSchemaProps) (*SchemaParameterTransformer, error) {
+	internalSchema := &apiextensions.JSONSchemaProps{}
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(schema, internalSchema, nil); err != nil {
+		return nil, errors.Wrap(err, "error converting schema")
+	}
+	structural, err := structuralschema.NewStructural(internalSchema)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid schema")
+	}
+	if errs := structuralschema.ValidateStructural(nil, structural); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "schema is not structural")
+	}
+	validator, _, err := apiservervalidation.NewSchemaValidator(&apiextensions.CustomResourceValidation{OpenAPIV3Schema: internalSchema})
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid schema")
+	}
+	return &SchemaParameterTransformer{
+		structural: structural,
+		validator:  validator,
+	}, nil
+}
+
+// Create a new SchemaParameterTransformer from a file (YAML or JSON). The file may contain either a schema,
+// or a custom resource definition; in the latter case, the schema of the spec field of the given version is used
+// (if version is empty, the storage version is used).
+func NewSchemaParameterTransformerFromFile(fsys fs.FS, path string, version string) (*SchemaParameterTransformer, error) {
+	if fsys == nil {
+		fsys = os.DirFS("/")
+		absolutePath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		path = absolutePath[1:]
+	}
+
+	raw, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	var document map[string]any
+	if err := kyaml.Unmarshal(raw, &document); err != nil {
+		return nil, errors.Wrapf(err, "error parsing %s", path)
+	}
+
+	if document["kind"] == "CustomResourceDefinition" {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := kyaml.UnmarshalStrict(raw, crd); err != nil {
+			return nil, errors.Wrapf(err, "error parsing custom resource definition %s", path)
+		}
+		schema, err := getSpecSchema(crd, version)
+		if err != nil {
+			return nil, err
+		}
+		return NewSchemaParameterTransformer(schema)
+	}
+
+	schema := &apiextensionsv1.JSONSchemaProps{}
+	if err := kyaml.UnmarshalStrict(raw, schema); err != nil {
+		return nil, errors.Wrapf(err, "error parsing schema %s", path)
+	}
+	return NewSchemaParameterTransformer(schema)
+}
+
+// Create a new SchemaParameterTransformer from the custom resource definition with the given name, as read from the cluster;
+// the schema of the spec field of the given version is used (if version is empty, the storage version is used).
+// Note that the custom resource definition is read only once; since this usually happens before the manager is started,
+// a non-cached reader (such as the manager's APIReader) should be passed.
+func NewSchemaParameterTransformerFromCrd(ctx context.Context, reader client.Reader, crdName string, version string) (*SchemaParameterTransformer, error) {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: crdName}, crd); err != nil {
+		return nil, errors.Wrapf(err, "error reading custom resource definition %s", crdName)
+	}
+	schema, err := getSpecSchema(crd, version)
+	if err != nil {
+		return nil, err
+	}
+	return NewSchemaParameterTransformer(schema)
+}
+
+// Default and validate parameters.
+func (t *SchemaParameterTransformer) TransformParameters(parameters types.Unstructurable) (types.Unstructurable, error) {
+	// note: round-trip through JSON to get a private copy, consisting of JSON values only (as required by the defaulting and validation logic)
+	raw, err := json.Marshal(parameters.ToUnstructured())
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]any
+	if err := utiljson.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+	if values == nil {
+		values = make(map[string]any)
+	}
+
+	defaultValues(values, t.structural)
+
+	if errs := apiservervalidation.ValidateCustomResource(field.NewPath("spec"), values, t.validator); len(errs) > 0 {
+		return nil, &ParameterValidationError{Errors: errs}
+	}
+
+	return types.UnstructurableMap(values), nil
+}
+
+// Default and validate parameters; since this is deterministic, the passed context and reconcile information are ignored.
+func (t *SchemaParameterTransformer) TransformParametersWithContext(ctx context.Context, info ReconcileInfo, parameters types.Unstructurable) (types.Unstructurable, error) {
+	return t.TransformParameters(parameters)
+}
+
+func getSpecSchema(crd *apiextensionsv1.CustomResourceDefinition, version string) (*apiextensionsv1.JSONSchemaProps, error) {
+	for _, v := range crd.Spec.Versions {
+		if version == "" && !v.Storage || version != "" && v.Name != version {
+			continue
+		}
+		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			return nil, fmt.Errorf("custom resource definition %s has no schema for version %s", crd.Name, v.Name)
+		}
+		schema, ok := v.Schema.OpenAPIV3Schema.Properties["spec"]
+		if !ok {
+			return nil, fmt.Errorf("schema of custom resource definition %s (version %s) has no spec field", crd.Name, v.Name)
+		}
+		return &schema, nil
+	}
+	if version == "" {
+		return nil, fmt.Errorf("custom resource definition %s has no storage version", crd.Name)
+	}
+	return nil, fmt.Errorf("custom resource definition %s has no version %s", crd.Name, version)
+}
+
+// apply defaults of the given structural schema to x (in place), in the same way as the Kubernetes API server does for custom resources
+// (note: this is equivalent to the defaulting package of apiextensions-apiserver, which is not used since it pulls in the CEL dependencies)
+func defaultValues(x any, s *structuralschema.Structural) {
+	if s == nil {
+		return
+	}
+	switch x := x.(type) {
+	case map[string]any:
+		for k, prop := range s.Properties {
+			if prop.Default.Object == nil {
+				continue
+			}
+			if _, found := x[k]; !found || isNonNullableNull(x[k], &prop) {
+				x[k] = runtime.DeepCopyJSONValue(prop.Default.Object)
+			}
+		}
+		for k := range x {
+			if prop, found := s.Properties[k]; found {
+				defaultValues(x[k], &prop)
+			} else if s.AdditionalProperties != nil {
+				if isNonNullableNull(x[k], s.AdditionalProperties.Structural) {
+					x[k] = runtime.DeepCopyJSONValue(s.AdditionalProperties.Structural.Default.Object)
+				}
+				defaultValues(x[k], s.AdditionalProperties.Structural)
+			}
+		}
+	case []any:
+		for i := range x {
+			if isNonNullableNull(x[i], s.Items) {
+				x[i] = runtime.DeepCopyJSONValue(s.Items.Default.Object)
+			}
+			defaultValues(x[i], s.Items)
+		}
+	}
+}
+
+func isNonNullableNull(x any, s *structuralschema.Structural) bool {
+	return x == nil && s != nil && !s.Generic.Nullable
+}
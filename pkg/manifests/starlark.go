@@ -0,0 +1,303 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifests
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/component-operator-runtime/pkg/types"
+)
+
+// Default limit for the number of execution steps of a single StarlarkGenerator run.
+const DefaultStarlarkMaxExecutionSteps = 10000000
+
+const starlarkGenerateFunction = "generate"
+
+// Maximum nesting depth of values passed to or returned from Starlark scripts.
+const starlarkMaxDepth = 100
+
+// StarlarkGenerator is a Generator implementation that executes a Starlark script.
+// The script must define a function generate(namespace, name, parameters), which returns a list of objects (dicts).
+// Scripts are executed in a sandbox: they cannot load other modules, print output is discarded, and only the
+// json module is predeclared (besides the Starlark universe); the number of execution steps is limited.
+type StarlarkGenerator struct {
+	program           *starlark.Program
+	predeclared       starlark.StringDict
+	maxExecutionSteps uint64
+}
+
+// StarlarkGeneratorOptions allows to tweak the behavior of the Starlark generator.
+type StarlarkGeneratorOptions struct {
+	// Maximum number of execution steps per Generate() call; if zero, DefaultStarlarkMaxExecutionSteps is used.
+	MaxExecutionSteps uint64
+}
+
+var _ Generator = &StarlarkGenerator{}
+
+// Create a new StarlarkGenerator.
+func NewStarlarkGenerator(fsys fs.FS, scriptPath string) (*StarlarkGenerator, error) {
+	return NewStarlarkGeneratorWithOptions(fsys, scriptPath, StarlarkGeneratorOptions{})
+}
+
+// Create a new StarlarkGenerator with options.
+func NewStarlarkGeneratorWithOptions(fsys fs.FS, scriptPath string, options StarlarkGeneratorOptions) (*StarlarkGenerator, error) {
+	g := StarlarkGenerator{
+		predeclared: starlark.StringDict{
+			"json": json.Module,
+		},
+		maxExecutionSteps: options.MaxExecutionSteps,
+	}
+	if g.maxExecutionSteps == 0 {
+		g.maxExecutionSteps = DefaultStarlarkMaxExecutionSteps
+	}
+
+	if fsys == nil {
+		fsys = os.DirFS("/")
+		absoluteScriptPath, err := filepath.Abs(scriptPath)
+		if err != nil {
+			return nil, err
+		}
+		scriptPath = absoluteScriptPath[1:]
+	}
+
+	raw, err := fs.ReadFile(fsys, scriptPath)
+	if err != nil {
+		return nil, err
+	}
+	// note: syntax and resolve errors already contain the position (file:line:col)
+	_, program, err := starlark.SourceProgram(filepath.Base(scriptPath), raw, g.predeclared.Has)
+	if err != nil {
+		return nil, errors.Wrap(err, "error compiling starlark script")
+	}
+	g.program = program
+
+	// run the script once to detect obvious errors early (such as a missing generate function)
+	if _, err := g.init(); err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+// Create a new StarlarkGenerator with a ParameterTransformer attached (further transformers can be attached to the reeturned generator object).
+func NewStarlarkGeneratorWithParameterTransformer(fsys fs.FS, scriptPath string, transformer ParameterTransformer) (TransformableGenerator, error) {
+	g, err := NewStarlarkGenerator(fsys, scriptPath)
+	if err != nil {
+		return nil, err
+	}
+	return NewGenerator(g).WithParameterTransformer(transformer), nil
+}
+
+// Create a new StarlarkGenerator with an ObjectTransformer attached (further transformers can be attached to the reeturned generator object).
+func NewStarlarkGeneratorWithObjectTransformer(fsys fs.FS, scriptPath string, transformer ObjectTransformer) (TransformableGenerator, error) {
+	g, err := NewStarlarkGenerator(fsys, scriptPath)
+	if err != nil {
+		return nil, err
+	}
+	return NewGenerator(g).WithObjectTransformer(transformer), nil
+}
+
+// Generate resource descriptors.
+func (g *StarlarkGenerator) Generate(namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	thread, err := g.init()
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := toStarlarkValue(parameters.ToUnstructured(), 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "error converting parameters")
+	}
+	result, err := starlark.Call(thread.thread, thread.generate, starlark.Tuple{starlark.String(namespace), starlark.String(name), values}, nil)
+	if err != nil {
+		return nil, formatStarlarkError(err)
+	}
+
+	iterable, ok := result.(starlark.Iterable)
+	if !ok || result.Type() == "string" || result.Type() == "dict" {
+		return nil, fmt.Errorf("%s() must return a list of objects (got %s)", starlarkGenerateFunction, result.Type())
+	}
+	var objects []client.Object
+	iter := iterable.Iterate()
+	defer iter.Done()
+	var item starlark.Value
+	for i := 0; iter.Next(&item); i++ {
+		if item == starlark.None {
+			continue
+		}
+		if _, ok := item.(*starlark.Dict); !ok {
+			return nil, fmt.Errorf("%s() must return a list of objects (item %d is %s)", starlarkGenerateFunction, i, item.Type())
+		}
+		object, err := fromStarlarkValue(item, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error converting object %d", i)
+		}
+		objects = append(objects, &unstructured.Unstructured{Object: object.(map[string]any)})
+	}
+
+	return objects, nil
+}
+
+type starlarkThread struct {
+	thread   *starlark.Thread
+	generate starlark.Callable
+}
+
+// execute the top-level statements of the script in a fresh thread, and return the thread together with the generate function
+func (g *StarlarkGenerator) init() (*starlarkThread, error) {
+	thread := &starlark.Thread{
+		Name:  "generate",
+		Print: func(thread *starlark.Thread, msg string) {},
+		// note: load statements will fail since Load is not set
+	}
+	thread.SetMaxExecutionSteps(g.maxExecutionSteps)
+
+	globals, err := g.program.Init(thread, g.predeclared)
+	if err != nil {
+		return nil, formatStarlarkError(err)
+	}
+	globals.Freeze()
+
+	generate, ok := globals[starlarkGenerateFunction].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("starlark script does not define a function %s()", starlarkGenerateFunction)
+	}
+
+	return &starlarkThread{thread: thread, generate: generate}, nil
+}
+
+// include the starlark backtrace (with positions) into evaluation errors
+func formatStarlarkError(err error) error {
+	if evalErr, ok := err.(*starlark.EvalError); ok {
+		return errors.New(evalErr.Backtrace())
+	}
+	return err
+}
+
+func toStarlarkValue(value any, depth int) (starlark.Value, error) {
+	if depth > starlarkMaxDepth {
+		return nil, fmt.Errorf("maximum nesting depth (%d) exceeded", starlarkMaxDepth)
+	}
+	switch value := value.(type) {
+	case nil:
+		return starlark.None, nil
+	case bool:
+		return starlark.Bool(value), nil
+	case string:
+		return starlark.String(value), nil
+	case int:
+		return starlark.MakeInt(value), nil
+	case int32:
+		return starlark.MakeInt64(int64(value)), nil
+	case int64:
+		return starlark.MakeInt64(value), nil
+	case float32:
+		return starlark.Float(value), nil
+	case float64:
+		return starlark.Float(value), nil
+	case []any:
+		elems := make([]starlark.Value, len(value))
+		for i, v := range value {
+			elem, err := toStarlarkValue(v, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = elem
+		}
+		return starlark.NewList(elems), nil
+	case map[string]any:
+		// note: insert keys in sorted order, since starlark dicts preserve insertion order
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		dict := starlark.NewDict(len(value))
+		for _, k := range keys {
+			v, err := toStarlarkValue(value[k], depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if err := dict.SetKey(starlark.String(k), v); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type: %T", value)
+	}
+}
+
+func fromStarlarkValue(value starlark.Value, depth int) (any, error) {
+	if depth > starlarkMaxDepth {
+		return nil, fmt.Errorf("maximum nesting depth (%d) exceeded", starlarkMaxDepth)
+	}
+	switch value := value.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(value), nil
+	case starlark.String:
+		return string(value), nil
+	case starlark.Int:
+		i, ok := value.Int64()
+		if !ok {
+			return nil, fmt.Errorf("integer value out of range: %s", value)
+		}
+		return i, nil
+	case starlark.Float:
+		return float64(value), nil
+	case *starlark.List, starlark.Tuple:
+		indexable := value.(starlark.Indexable)
+		result := make([]any, indexable.Len())
+		for i := 0; i < indexable.Len(); i++ {
+			v, err := fromStarlarkValue(indexable.Index(i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = v
+		}
+		return result, nil
+	case *starlark.Dict:
+		result := make(map[string]any, value.Len())
+		for _, item := range value.Items() {
+			k, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("dict keys must be strings (got %s)", item[0].Type())
+			}
+			v, err := fromStarlarkValue(item[1], depth+1)
+			if err != nil {
+				return nil, err
+			}
+			result[string(k)] = v
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type: %s", value.Type())
+	}
+}
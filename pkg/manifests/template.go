@@ -0,0 +1,181 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifests
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/component-operator-runtime/internal/helm"
+	"github.com/sap/component-operator-runtime/internal/templatex"
+	"github.com/sap/component-operator-runtime/pkg/types"
+)
+
+// TemplateGenerator is a Generator implementation that renders a directory of go-templated manifests.
+// All files ending with .yaml, .yml or .tpl are considered; files whose name starts with an underscore
+// (such as _helpers.tpl) are not rendered, but can be included by other templates.
+type TemplateGenerator struct {
+	client    client.Client
+	templates []*template.Template
+}
+
+var _ GeneratorWithContext = &TemplateGenerator{}
+
+// Create a new TemplateGenerator.
+func NewTemplateGenerator(fsys fs.FS, templatePath string, client client.Client) (*TemplateGenerator, error) {
+	g := TemplateGenerator{client: client}
+
+	if fsys == nil {
+		fsys = os.DirFS("/")
+		absoluteTemplatePath, err := filepath.Abs(templatePath)
+		if err != nil {
+			return nil, err
+		}
+		templatePath = absoluteTemplatePath[1:]
+	}
+
+	var t *template.Template
+	if err := fs.WalkDir(
+		fsys,
+		templatePath,
+		func(path string, dirEntry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !dirEntry.Type().IsRegular() {
+				return nil
+			}
+			if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" && ext != ".tpl" {
+				return nil
+			}
+			raw, err := fs.ReadFile(fsys, path)
+			if err != nil {
+				return err
+			}
+			name, err := filepath.Rel(templatePath, path)
+			if err != nil {
+				// TODO: is it ok to panic here in case of error ?
+				panic(err)
+			}
+			if t == nil {
+				t = template.New(name)
+			} else {
+				t = t.New(name)
+			}
+			t.Option("missingkey=zero").
+				Funcs(sprig.TxtFuncMap()).
+				Funcs(templatex.FuncMap()).
+				Funcs(templatex.FuncMapForTemplate(t)).
+				Funcs(templatex.FuncMapForClient(client))
+			if _, err := t.Parse(string(raw)); err != nil {
+				return err
+			}
+			if !strings.HasPrefix(filepath.Base(name), "_") {
+				g.templates = append(g.templates, t)
+			}
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+// Create a new TemplateGenerator with a ParameterTransformer attached (further transformers can be attached to the reeturned generator object).
+func NewTemplateGeneratorWithParameterTransformer(fsys fs.FS, templatePath string, client client.Client, transformer ParameterTransformer) (TransformableGenerator, error) {
+	g, err := NewTemplateGenerator(fsys, templatePath, client)
+	if err != nil {
+		return nil, err
+	}
+	return NewGenerator(g).WithParameterTransformer(transformer), nil
+}
+
+// Create a new TemplateGenerator with an ObjectTransformer attached (further transformers can be attached to the reeturned generator object).
+func NewTemplateGeneratorWithObjectTransformer(fsys fs.FS, templatePath string, client client.Client, transformer ObjectTransformer) (TransformableGenerator, error) {
+	g, err := NewTemplateGenerator(fsys, templatePath, client)
+	if err != nil {
+		return nil, err
+	}
+	return NewGenerator(g).WithObjectTransformer(transformer), nil
+}
+
+// Generate resource descriptors.
+func (g *TemplateGenerator) Generate(namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	return g.generate(context.Background(), nil, namespace, name, parameters)
+}
+
+// Generate resource descriptors, using the given context (and the reconciler's client, if passed) for lookups.
+func (g *TemplateGenerator) GenerateWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	return g.generate(ctx, info.Client, namespace, name, parameters)
+}
+
+// generate resource descriptors; lookups use the given context and client (or the generator's client, if nil)
+func (g *TemplateGenerator) generate(ctx context.Context, c client.Client, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error) {
+	var objects []client.Object
+
+	data := map[string]any{
+		"Values": parameters.ToUnstructured(),
+		"Release": &helm.ReleaseData{
+			Namespace: namespace,
+			Name:      name,
+		},
+	}
+
+	if c == nil {
+		c = g.client
+	}
+	templates, err := cloneTemplates(ctx, g.templates, c)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range templates {
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		decoder := utilyaml.NewYAMLToJSONDecoder(&buf)
+		for {
+			object := &unstructured.Unstructured{}
+			if err := decoder.Decode(&object.Object); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, err
+			}
+			if object.Object == nil {
+				continue
+			}
+			objects = append(objects, object)
+		}
+	}
+
+	return objects, nil
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package manifests
 
 import (
+	"context"
+
+	apitypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/sap/component-operator-runtime/pkg/types"
@@ -31,11 +34,82 @@ type Generator interface {
 	Generate(namespace string, name string, parameters types.Unstructurable) ([]client.Object, error)
 }
 
+// Release information, as passed by the reconciler to generators implementing ReleaseAwareGenerator.
+type ReleaseInfo struct {
+	// Whether the component is being installed; that is, it was never successfully applied,
+	// or there are no dependent objects deployed so far (that is, the component's inventory is empty).
+	IsInstall bool
+	// Whether the component is being upgraded (the opposite of IsInstall).
+	IsUpgrade bool
+	// Revision of the release; this is the generation of the component.
+	Revision int64
+}
+
+// Optional interface for generators which make use of release information (e.g. to distinguish installs from upgrades).
+// If a generator implements this interface, the reconciler calls GenerateRelease() instead of Generate().
+type ReleaseAwareGenerator interface {
+	Generator
+	GenerateRelease(namespace string, name string, parameters types.Unstructurable, release ReleaseInfo) ([]client.Object, error)
+}
+
+// Optional interface for generators which produce usage notes (such as the NOTES.txt of Helm charts).
+// If a generator implements this interface, the reconciler renders the notes after the component became ready,
+// and exposes them in the component's status.
+type NotesGenerator interface {
+	Generator
+	GenerateNotes(namespace string, name string, parameters types.Unstructurable, release ReleaseInfo) (string, error)
+}
+
+// Context-aware variant of the NotesGenerator interface.
+// If a generator implements this interface, the reconciler calls GenerateNotesWithContext() instead of GenerateNotes(),
+// passing the same context and reconcile information as to GenerateWithContext().
+type NotesGeneratorWithContext interface {
+	NotesGenerator
+	GenerateNotesWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) (string, error)
+}
+
+// Read-only view of the reconciled component, as passed (through ReconcileInfo) to context-aware generators and transformers.
+type ComponentView interface {
+	GetNamespace() string
+	GetName() string
+	GetDeploymentNamespace() string
+	GetUID() apitypes.UID
+	GetGeneration() int64
+	GetLabels() map[string]string
+	GetAnnotations() map[string]string
+}
+
+// Information about the reconciliation, as passed to context-aware generators and transformers.
+// When called by the reconciler, all fields are set; otherwise (for example, if a generator is called through Generate() or GenerateRelease()),
+// some or all fields may be nil.
+type ReconcileInfo struct {
+	// Read-only view (actually a copy) of the reconciled component.
+	Component ComponentView
+	// The reconciler's client.
+	Client client.Client
+	// Release information (the same as passed to ReleaseAwareGenerator).
+	Release *ReleaseInfo
+}
+
+// Context-aware variant of the Generator interface.
+// Besides the usual arguments, GenerateWithContext() receives a context and information about the reconciliation
+// (such as a read-only view of the reconciled component, and the reconciler's client).
+type GeneratorWithContext interface {
+	Generator
+	GenerateWithContext(ctx context.Context, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) ([]client.Object, error)
+}
+
 // Interface for generators that can be enhanced with parameter/object transformers.
+// Context-aware transformers are only passed a context and complete reconcile information if the generator is called through GenerateWithContext();
+// otherwise they receive context.Background() and (apart from the release information passed to GenerateRelease()) empty reconcile information.
+// Transformers passed to WithParameterTransformer() or WithObjectTransformer() which also implement the according context-aware interface
+// are called through that interface.
 type TransformableGenerator interface {
-	Generator
+	GeneratorWithContext
 	WithParameterTransformer(transformer ParameterTransformer) TransformableGenerator
 	WithObjectTransformer(transformer ObjectTransformer) TransformableGenerator
+	WithParameterTransformerWithContext(transformer ParameterTransformerWithContext) TransformableGenerator
+	WithObjectTransformerWithContext(transformer ObjectTransformerWithContext) TransformableGenerator
 }
 
 // Parameter transformer interface.
@@ -49,3 +123,13 @@ type ParameterTransformer interface {
 type ObjectTransformer interface {
 	TransformObjects(objects []client.Object) ([]client.Object, error)
 }
+
+// Context-aware variant of the ParameterTransformer interface.
+type ParameterTransformerWithContext interface {
+	TransformParametersWithContext(ctx context.Context, info ReconcileInfo, parameters types.Unstructurable) (types.Unstructurable, error)
+}
+
+// Context-aware variant of the ObjectTransformer interface.
+type ObjectTransformerWithContext interface {
+	TransformObjectsWithContext(ctx context.Context, info ReconcileInfo, objects []client.Object) ([]client.Object, error)
+}
@@ -17,7 +17,14 @@ limitations under the License.
 package manifests
 
 import (
+	"context"
+	"text/template"
+
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/component-operator-runtime/internal/templatex"
+	"github.com/sap/component-operator-runtime/pkg/types"
 )
 
 // Deep-merge two maps with the usual logic and return the result.
@@ -46,3 +53,32 @@ func MergeMaps(x, y map[string]any) map[string]any {
 	}
 	return x
 }
+
+// generate notes by calling GenerateNotesWithContext() if the given generator implements NotesGeneratorWithContext,
+// and GenerateNotes() otherwise (in which case the release defaults to an install with revision 1, if no release information is passed)
+func generateNotes(ctx context.Context, generator NotesGenerator, info ReconcileInfo, namespace string, name string, parameters types.Unstructurable) (string, error) {
+	if generator, ok := generator.(NotesGeneratorWithContext); ok {
+		return generator.GenerateNotesWithContext(ctx, info, namespace, name, parameters)
+	}
+	release := ReleaseInfo{IsInstall: true, Revision: 1}
+	if info.Release != nil {
+		release = *info.Release
+	}
+	return generator.GenerateNotes(namespace, name, parameters, release)
+}
+
+// clone the given (associated) templates, such that lookups are executed with the given context and client
+func cloneTemplates(ctx context.Context, templates []*template.Template, client client.Client) ([]*template.Template, error) {
+	if len(templates) == 0 {
+		return nil, nil
+	}
+	clone, err := templatex.CloneWithContext(ctx, templates[0], client)
+	if err != nil {
+		return nil, err
+	}
+	clonedTemplates := make([]*template.Template, len(templates))
+	for i, t := range templates {
+		clonedTemplates[i] = clone.Lookup(t.Name())
+	}
+	return clonedTemplates, nil
+}